@@ -16,8 +16,9 @@ import (
 	"github.com/influxdata/influxdb/client/v2"
 	"github.com/namsral/flag"
 	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/agent/influx"
 	"github.com/telekom/k8s-edge-scheduler/cache"
-	appsv1 "k8s.io/api/apps/v1"
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -25,19 +26,12 @@ const writePeriod = 15 * time.Second
 
 var (
 	log             *logrus.Entry
-	influxAddr      string
-	influxUser      string
-	influxPassword  string
-	influxDB        string
 	proxyNamespace  string
 	nsIgnorePattern string
 	databasePrefix  string
 )
 
 func init() {
-	flag.StringVar(&influxAddr, "influxAddr", "http://influxdb:8086", "influxdb address")
-	flag.StringVar(&influxUser, "influxUser", "influx", "influxdb user")
-	flag.StringVar(&influxPassword, "influxPassword", "influx", "influxdb user password")
 	flag.StringVar(&proxyNamespace, "proxyNamespace", "open-edge-cloud", "proxy namespace")
 	flag.StringVar(&nsIgnorePattern, "nsIgnoreRegex", "", "ignore pattern for system namespaces")
 	flag.StringVar(&databasePrefix, "databasePrefix", "edge-", "database prefix")
@@ -49,15 +43,15 @@ type Collector struct {
 	batch  map[string]client.BatchPoints
 	kube   KubernetesClient
 
-	deploymentsByPodIP *cache.Cache
-	proxiesByName      *cache.Cache
-	blacklistedIPs     *cache.Cache
+	workloadsByPodIP *cache.Cache
+	proxiesByName    *cache.Cache
+	blacklistedIPs   *cache.Cache
 }
 
 type KubernetesClient interface {
 	GetPodByIPAllNamespaces(ip string) (*v1.Pod, error)
 	GetPod(name string, namespace string) (*v1.Pod, error)
-	GetDeploymentFromPod(p *v1.Pod) (*appsv1.Deployment, error)
+	GetWorkloadFromPod(p *v1.Pod) (*kubeclient.Workload, error)
 	GetLocationFromPod(p *v1.Pod) (string, error)
 }
 
@@ -66,23 +60,19 @@ func NewCollector(k KubernetesClient, l *logrus.Logger) *Collector {
 		"component": "collector",
 	})
 
-	i, err := client.NewHTTPClient(client.HTTPConfig{
-		Addr:     influxAddr,
-		Username: influxUser,
-		Password: influxPassword,
-	})
+	i, err := influx.NewClient()
 	if err != nil {
 		log.Fatalf("cannot connect to influxdb: %s", err.Error())
 	}
-	log.Infof("connected to influxdb %s", influxAddr)
+	log.Infof("connected to influxdb %s", influx.Addr())
 
 	c := &Collector{
-		influx:             i,
-		batch:              make(map[string]client.BatchPoints),
-		deploymentsByPodIP: cache.NewCache(),
-		proxiesByName:      cache.NewCache(),
-		blacklistedIPs:     cache.NewCache(),
-		kube:               k,
+		influx:           i,
+		batch:            make(map[string]client.BatchPoints),
+		workloadsByPodIP: cache.NewCache(),
+		proxiesByName:    cache.NewCache(),
+		blacklistedIPs:   cache.NewCache(),
+		kube:             k,
 	}
 	c.blacklistedIPs.Timeout = time.Hour
 
@@ -103,7 +93,7 @@ func (c *Collector) AddRequest(ip string, proxy string, hostname string, timesta
 		return
 	}
 
-	d, err := c.getDeploymentNameByPodIP(ip)
+	w, err := c.getWorkloadByPodIP(ip)
 	if err != nil {
 		log.Warn(err.Error())
 		return
@@ -114,19 +104,20 @@ func (c *Collector) AddRequest(ip string, proxy string, hostname string, timesta
 		return
 	}
 
-	db := fmt.Sprintf("%s%s", databasePrefix, d.namespace)
+	db := fmt.Sprintf("%s%s", databasePrefix, w.Namespace)
 
-	log.Debugf("ip: %s, proxy: %s, timestamp: %d, source: %s, destination: %s, database: %s", ip, proxy, timestamp, src, d.name, db)
+	log.Debugf("ip: %s, proxy: %s, timestamp: %d, source: %s, destination: %s, database: %s", ip, proxy, timestamp, src, w, db)
 
 	tags := map[string]string{
 		"location": src,
-		"app":      d.name,
+		"app":      w.Name,
+		"kind":     w.Kind,
 	}
 	fields := map[string]interface{}{
 		"duration":    duration,
 		"proxy":       proxy,
 		"source":      src,
-		"destination": d.name,
+		"destination": w.Name,
 	}
 
 	t := strconv.FormatInt(timestamp, 10)
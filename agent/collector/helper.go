@@ -10,16 +10,13 @@ package collector
 import (
 	"fmt"
 	"regexp"
-)
 
-type deployment struct {
-	name      string
-	namespace string
-}
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
+)
 
-func (c *Collector) getDeploymentNameByPodIP(ip string) (*deployment, error) {
-	if i, ok := c.deploymentsByPodIP.Get(ip); ok {
-		return i.(*deployment), nil
+func (c *Collector) getWorkloadByPodIP(ip string) (*kubeclient.Workload, error) {
+	if i, ok := c.workloadsByPodIP.Get(ip); ok {
+		return i.(*kubeclient.Workload), nil
 	}
 
 	p, err := c.kube.GetPodByIPAllNamespaces(ip)
@@ -34,19 +31,14 @@ func (c *Collector) getDeploymentNameByPodIP(ip string) (*deployment, error) {
 		return nil, fmt.Errorf("namespace ignore pattern failed: %s", err.Error())
 	}
 
-	d, err := c.kube.GetDeploymentFromPod(p)
+	w, err := c.kube.GetWorkloadFromPod(p)
 	if err != nil {
 		return nil, err
 	}
 
-	i := &deployment{
-		name:      d.Name,
-		namespace: d.Namespace,
-	}
-
-	c.deploymentsByPodIP.Set(ip, i)
+	c.workloadsByPodIP.Set(ip, w)
 
-	return i, nil
+	return w, nil
 }
 
 func (c *Collector) getProxyLocation(name string) (string, error) {
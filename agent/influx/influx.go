@@ -0,0 +1,38 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package influx
+
+import (
+	"github.com/influxdata/influxdb/client/v2"
+	"github.com/namsral/flag"
+)
+
+var (
+	influxAddr     string
+	influxUser     string
+	influxPassword string
+)
+
+func init() {
+	flag.StringVar(&influxAddr, "influxAddr", "http://influxdb:8086", "influxdb address")
+	flag.StringVar(&influxUser, "influxUser", "influx", "influxdb user")
+	flag.StringVar(&influxPassword, "influxPassword", "influx", "influxdb user password")
+}
+
+// NewClient connects to the influxdb given by the flags.
+func NewClient() (client.Client, error) {
+	return client.NewHTTPClient(client.HTTPConfig{
+		Addr:     influxAddr,
+		Username: influxUser,
+		Password: influxPassword,
+	})
+}
+
+func Addr() string {
+	return influxAddr
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/telekom/k8s-edge-scheduler/agent/collector"
 	"github.com/telekom/k8s-edge-scheduler/agent/jaegeragent"
+	"github.com/telekom/k8s-edge-scheduler/agent/probe"
 	"github.com/telekom/k8s-edge-scheduler/kubeclient"
 )
 
 func main() {
 	debug := flag.Bool("debug", false, "enable debugging")
+	mode := flag.String("mode", "collector", "collector to write requests traced by jaeger, probe to measure the round trip times to other locations")
 	flag.Parse()
 
 	log := logrus.New()
@@ -25,6 +27,13 @@ func main() {
 	}
 
 	kube := kubeclient.NewKubeClient(log, kubeclient.NewClientset())
+	if *mode == "probe" {
+		probe.NewProbe(kube, log).Start()
+		return
+	} else if *mode != "collector" {
+		log.Fatalf("unknown mode %s", *mode)
+	}
+
 	collector := collector.NewCollector(kube, log)
 	agent := jaegeragent.NewJaegerAgent(collector, log)
 
@@ -0,0 +1,98 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const payloadSize = 16
+
+// serveUDP sends every packet back to its sender.
+func serveUDP(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			log.Warnf("udp echo: %s", err.Error())
+			continue
+		}
+		if _, err := conn.WriteTo(buf[:n], from); err != nil {
+			log.Debugf("udp echo to %s: %s", from, err.Error())
+		}
+	}
+}
+
+// serveTCP sends everything back on every connection.
+func serveTCP(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			log.Warnf("tcp echo: %s", err.Error())
+			continue
+		}
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	}
+}
+
+// echo measures the round trip times of count packets to an echo server, lost packets are left out.
+func echo(protocol string, addr string, count int, timeout time.Duration) ([]time.Duration, error) {
+	conn, err := net.DialTimeout(protocol, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var rtts []time.Duration
+	req := make([]byte, payloadSize)
+	res := make([]byte, payloadSize)
+	for i := 0; i < count; i++ {
+		binary.BigEndian.PutUint64(req, uint64(i))
+		binary.BigEndian.PutUint64(req[8:], uint64(time.Now().UnixNano()))
+		start := time.Now()
+		conn.SetDeadline(start.Add(timeout))
+		if _, err := conn.Write(req); err != nil {
+			return rtts, err
+		}
+		for {
+			if _, err := io.ReadFull(conn, res); err != nil {
+				if protocol == "tcp" {
+					return rtts, err
+				}
+				break
+			}
+			// late answers of earlier packets don't count for this one
+			if bytes.Equal(req, res) {
+				rtts = append(rtts, time.Since(start))
+				break
+			}
+		}
+	}
+	if len(rtts) == 0 {
+		return nil, fmt.Errorf("no answer from %s", addr)
+	}
+	return rtts, nil
+}
@@ -0,0 +1,247 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package probe
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/client/v2"
+	"github.com/namsral/flag"
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/agent/influx"
+	"github.com/telekom/k8s-edge-scheduler/cache"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const measurement = "rtt"
+
+var (
+	log            *logrus.Entry
+	location       string
+	nodeName       string
+	podIP          string
+	probePort      int
+	probeProtocol  string
+	probeInterval  time.Duration
+	probeTimeout   time.Duration
+	probeCount     int
+	probeSelector  string
+	probeNamespace string
+	probeDB        string
+)
+
+func init() {
+	flag.StringVar(&location, "location", "", "location of this probe, taken from the node if empty")
+	flag.StringVar(&nodeName, "nodeName", "", "node of this probe")
+	flag.StringVar(&podIP, "podIP", "", "ip of this probe, to leave it out of the peers")
+	flag.IntVar(&probePort, "probePort", 7777, "port of the tcp and udp echo server")
+	flag.StringVar(&probeProtocol, "probeProtocol", "udp", "protocol to probe peers, udp or tcp")
+	flag.DurationVar(&probeInterval, "probeInterval", 30*time.Second, "interval to probe all peers")
+	flag.DurationVar(&probeTimeout, "probeTimeout", time.Second, "timeout of a single probe")
+	flag.IntVar(&probeCount, "probeCount", 5, "number of packets sent to every peer per interval")
+	flag.StringVar(&probeSelector, "probeSelector", "app=edge-scheduler-probe", "label selector of the peer probes")
+	flag.StringVar(&probeNamespace, "probeNamespace", metav1.NamespaceDefault, "namespace of the peer probes")
+	flag.StringVar(&probeDB, "probeDB", "edge-latency", "influxdb database of the measured round trip times")
+}
+
+type KubernetesClient interface {
+	GetClientset() *kubernetes.Clientset
+	GetLocationFromNode(n *v1.Node) (string, error)
+}
+
+// Probe measures the round trip time and jitter from its location to the locations of its peers.
+type Probe struct {
+	influx    client.Client
+	kube      KubernetesClient
+	namespace string
+	location  string
+	locations *cache.Cache
+}
+
+type result struct {
+	rtt    float64
+	jitter float64
+	loss   float64
+}
+
+func NewProbe(k KubernetesClient, l *logrus.Logger) *Probe {
+	log = l.WithFields(logrus.Fields{
+		"component": "probe",
+	})
+
+	i, err := influx.NewClient()
+	if err != nil {
+		log.Fatalf("cannot connect to influxdb: %s", err.Error())
+	}
+	log.Infof("connected to influxdb %s", influx.Addr())
+
+	p := &Probe{
+		influx:    i,
+		kube:      k,
+		namespace: probeNamespace,
+		location:  location,
+		locations: cache.NewCache(),
+	}
+	p.locations.Timeout = 10 * time.Minute
+
+	if p.location == "" {
+		l, err := p.nodeLocation(nodeName)
+		if err != nil {
+			log.Fatalf("no location for this probe: %s", err.Error())
+		}
+		p.location = l
+	}
+	return p
+}
+
+func (p *Probe) Start() {
+	defer p.influx.Close()
+	addr := ":" + strconv.Itoa(probePort)
+	go func() {
+		log.Fatal(serveUDP(addr))
+	}()
+	go func() {
+		log.Fatal(serveTCP(addr))
+	}()
+	log.Infof("probe peers from location %s, echo on %s", p.location, addr)
+
+	for {
+		p.probe()
+		<-time.NewTimer(probeInterval).C
+	}
+}
+
+// probe measures all peers and writes the mean by peer location.
+func (p *Probe) probe() {
+	pods, err := p.kube.GetClientset().CoreV1().Pods(p.namespace).List(metav1.ListOptions{
+		LabelSelector: probeSelector,
+	})
+	if err != nil {
+		log.Warnf("cannot list peers: %s", err.Error())
+		return
+	}
+
+	results := make(map[string][]result)
+	for _, peer := range pods.Items {
+		if peer.Status.PodIP == "" || peer.Status.PodIP == podIP || peer.Status.Phase != v1.PodRunning {
+			continue
+		}
+		dst, err := p.nodeLocation(peer.Spec.NodeName)
+		if err != nil {
+			log.Warn(err.Error())
+			continue
+		}
+		rtts, err := echo(probeProtocol, net.JoinHostPort(peer.Status.PodIP, strconv.Itoa(probePort)), probeCount, probeTimeout)
+		if err != nil {
+			log.Warnf("probe %s at %s: %s", peer.Name, dst, err.Error())
+			results[dst] = append(results[dst], result{loss: 1})
+			continue
+		}
+		results[dst] = append(results[dst], measure(rtts))
+	}
+
+	bp, err := client.NewBatchPoints(client.BatchPointsConfig{
+		Database:  probeDB,
+		Precision: "ms",
+	})
+	if err != nil {
+		log.Warn(err.Error())
+		return
+	}
+	now := time.Now()
+	for dst, rs := range results {
+		r := mean(rs)
+		log.Debugf("%s -> %s: rtt %.2f ms, jitter %.2f ms, loss %.2f", p.location, dst, r.rtt, r.jitter, r.loss)
+		fields := map[string]interface{}{
+			"loss": r.loss,
+		}
+		// unreachable locations have no round trip time
+		if r.loss < 1 {
+			fields["rtt"] = r.rtt
+			fields["jitter"] = r.jitter
+		}
+		pt, err := client.NewPoint(measurement, map[string]string{
+			"src_location": p.location,
+			"dst_location": dst,
+		}, fields, now)
+		if err != nil {
+			log.Warnf("cannot create new point: %s", err.Error())
+			continue
+		}
+		bp.AddPoint(pt)
+	}
+	if len(bp.Points()) == 0 {
+		return
+	}
+	if err := p.influx.Write(bp); err != nil {
+		log.Warnf("cannot write points to database %s: %s", probeDB, err.Error())
+	}
+}
+
+func (p *Probe) nodeLocation(name string) (string, error) {
+	if l, ok := p.locations.Get(name); ok {
+		return l.(string), nil
+	}
+	n, err := p.kube.GetClientset().CoreV1().Nodes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	l, err := p.kube.GetLocationFromNode(n)
+	if err != nil {
+		return "", err
+	}
+	p.locations.Set(name, l)
+	return l, nil
+}
+
+// measure returns the mean round trip time and the mean difference of consecutive round trip times in ms.
+func measure(rtts []time.Duration) result {
+	r := result{
+		loss: 1 - float64(len(rtts))/float64(probeCount),
+	}
+	for i, d := range rtts {
+		r.rtt += ms(d)
+		if i > 0 {
+			r.jitter += math.Abs(ms(d) - ms(rtts[i-1]))
+		}
+	}
+	r.rtt /= float64(len(rtts))
+	if len(rtts) > 1 {
+		r.jitter /= float64(len(rtts) - 1)
+	}
+	return r
+}
+
+// mean of several peers at the same location, unreachable peers only count for the loss.
+func mean(rs []result) result {
+	m := result{}
+	reached := 0
+	for _, r := range rs {
+		m.loss += r.loss
+		if r.loss < 1 {
+			m.rtt += r.rtt
+			m.jitter += r.jitter
+			reached++
+		}
+	}
+	m.loss /= float64(len(rs))
+	if reached > 0 {
+		m.rtt /= float64(reached)
+		m.jitter /= float64(reached)
+	}
+	return m
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
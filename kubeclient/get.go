@@ -10,7 +10,6 @@ package kubeclient
 import (
 	"fmt"
 
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -60,65 +59,3 @@ func (k *KubeClient) GetPodByIPAllNamespaces(ip string) (*v1.Pod, error) {
 
 	return nil, fmt.Errorf("no pod with ip %s found in any namespace", ip)
 }
-
-func (k *KubeClient) GetDeploymentFromPod(p *v1.Pod) (*appsv1.Deployment, error) {
-	if len(p.OwnerReferences) == 0 {
-		return nil, fmt.Errorf("pod %s has no owner reference", p.Name)
-	}
-	r, err := k.clientset.AppsV1().ReplicaSets(p.Namespace).Get(p.OwnerReferences[0].Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("replica set %s not found", p.OwnerReferences[0].Name)
-	}
-	if len(r.OwnerReferences) == 0 {
-		return nil, fmt.Errorf("replicaset %s has no owner reference", r.Name)
-	}
-	d, err := k.clientset.AppsV1().Deployments(r.Namespace).Get(r.OwnerReferences[0].Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("deployment %s not found", r.OwnerReferences[0].Name)
-	}
-	return d, nil
-}
-
-func (k *KubeClient) GetPodsFromDeployment(d *appsv1.Deployment) ([]v1.Pod, error) {
-	s, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
-	if err != nil {
-		return nil, err
-	}
-
-	rs, err := k.clientset.AppsV1().ReplicaSets(d.Namespace).List(metav1.ListOptions{
-		LabelSelector: s.String(),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if len(rs.Items) == 0 {
-		return nil, fmt.Errorf("deployment %s has no replicaset", d.Name)
-	}
-	var l []v1.Pod
-	for _, i := range rs.Items {
-		p, err := k.GetPodsFromReplicaSet(&i)
-		if err != nil {
-			log.Warn(err.Error())
-		} else {
-			l = append(l, p...)
-		}
-	}
-	return l, nil
-}
-
-func (k *KubeClient) GetPodsFromReplicaSet(r *appsv1.ReplicaSet) ([]v1.Pod, error) {
-	s, err := metav1.LabelSelectorAsSelector(r.Spec.Selector)
-	if err != nil {
-		return nil, err
-	}
-
-	p, err := k.clientset.CoreV1().Pods(r.Namespace).List(metav1.ListOptions{
-		LabelSelector: s.String(),
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return p.Items, nil
-}
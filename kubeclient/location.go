@@ -10,10 +10,28 @@ package kubeclient
 import (
 	"fmt"
 
+	"github.com/namsral/flag"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	zoneLabel   = "topology.kubernetes.io/zone"
+	regionLabel = "topology.kubernetes.io/region"
+)
+
+var (
+	locationLabel         string
+	locationAnnotation    string
+	unlabeledNodeLocation string
+)
+
+func init() {
+	flag.StringVar(&locationLabel, "locationLabel", "location", "node label of the location")
+	flag.StringVar(&locationAnnotation, "locationAnnotation", "edge-scheduler/location", "node annotation of the location, used if the node has neither the location label nor a zone or region label")
+	flag.StringVar(&unlabeledNodeLocation, "unlabeledNodeLocation", "", "location of nodes without location label or annotation, empty to leave them out")
+}
+
 func (k *KubeClient) GetLocationFromPod(p *v1.Pod) (string, error) {
 	n, err := k.clientset.CoreV1().Nodes().Get(p.Spec.NodeName, metav1.GetOptions{})
 	if err != nil {
@@ -22,9 +40,19 @@ func (k *KubeClient) GetLocationFromPod(p *v1.Pod) (string, error) {
 	return k.GetLocationFromNode(n)
 }
 
+// GetLocationFromNode returns the first of the location label, the zone label,
+// the region label and the location annotation the node has, or else the location of unlabeled nodes.
 func (k *KubeClient) GetLocationFromNode(n *v1.Node) (string, error) {
-	if l, ok := n.Labels["location"]; ok {
+	for _, key := range []string{locationLabel, zoneLabel, regionLabel} {
+		if l, ok := n.Labels[key]; ok && l != "" {
+			return l, nil
+		}
+	}
+	if l, ok := n.Annotations[locationAnnotation]; ok && l != "" {
 		return l, nil
 	}
-	return "", fmt.Errorf("node %s has no label 'location'", n.Name)
+	if unlabeledNodeLocation != "" {
+		return unlabeledNodeLocation, nil
+	}
+	return "", fmt.Errorf("node %s has no label '%s', '%s' or '%s' and no annotation '%s'", n.Name, locationLabel, zoneLabel, regionLabel, locationAnnotation)
 }
@@ -0,0 +1,175 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package kubeclient
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	KindDeployment            = "Deployment"
+	KindReplicaSet            = "ReplicaSet"
+	KindStatefulSet           = "StatefulSet"
+	KindDaemonSet             = "DaemonSet"
+	KindJob                   = "Job"
+	KindCronJob               = "CronJob"
+	KindReplicationController = "ReplicationController"
+	// KindPod is the workload of a pod without controller
+	KindPod = "Pod"
+)
+
+// Workload is the top most controller of a pod, e.g. the deployment of its replica set,
+// or the pod itself if no controller owns it.
+type Workload struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+
+	// selects the pods of the workload, nil if the pods are found by their owner
+	selector labels.Selector
+}
+
+// Key identifies the workload, e.g. default/Deployment/nginx.
+func (w *Workload) Key() string {
+	return w.Namespace + "/" + w.Kind + "/" + w.Name
+}
+
+func (w *Workload) String() string {
+	return w.Kind + " " + w.Namespace + "/" + w.Name
+}
+
+// Movable reports if a new pod replaces an evicted pod of the workload somewhere else.
+func (w *Workload) Movable() bool {
+	return w.Kind != KindPod && w.Kind != KindDaemonSet
+}
+
+// GetWorkloadFromPod follows the controller references of a pod to its workload.
+func (k *KubeClient) GetWorkloadFromPod(p *v1.Pod) (*Workload, error) {
+	ref := metav1.GetControllerOf(p)
+	if ref == nil {
+		return &Workload{
+			Kind:      KindPod,
+			Namespace: p.Namespace,
+			Name:      p.Name,
+			UID:       p.UID,
+		}, nil
+	}
+
+	w := &Workload{
+		Kind:      ref.Kind,
+		Namespace: p.Namespace,
+		Name:      ref.Name,
+		UID:       ref.UID,
+	}
+	var selector *metav1.LabelSelector
+	switch ref.Kind {
+	case KindReplicaSet:
+		r, err := k.clientset.AppsV1().ReplicaSets(p.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("replica set %s of pod %s not found", ref.Name, p.Name)
+		}
+		selector = r.Spec.Selector
+		if o := metav1.GetControllerOf(r); o != nil && o.Kind == KindDeployment {
+			d, err := k.clientset.AppsV1().Deployments(p.Namespace).Get(o.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("deployment %s of replica set %s not found", o.Name, r.Name)
+			}
+			w.Kind, w.Name, w.UID = KindDeployment, d.Name, d.UID
+			selector = d.Spec.Selector
+		}
+	case KindStatefulSet:
+		s, err := k.clientset.AppsV1().StatefulSets(p.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("stateful set %s of pod %s not found", ref.Name, p.Name)
+		}
+		selector = s.Spec.Selector
+	case KindDaemonSet:
+		d, err := k.clientset.AppsV1().DaemonSets(p.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("daemon set %s of pod %s not found", ref.Name, p.Name)
+		}
+		selector = d.Spec.Selector
+	case KindJob:
+		j, err := k.clientset.BatchV1().Jobs(p.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("job %s of pod %s not found", ref.Name, p.Name)
+		}
+		selector = j.Spec.Selector
+		// the pods of all jobs of a cron job are found by their owner
+		if o := metav1.GetControllerOf(j); o != nil && o.Kind == KindCronJob {
+			w.Kind, w.Name, w.UID = KindCronJob, o.Name, o.UID
+			return w, nil
+		}
+	case KindReplicationController:
+		r, err := k.clientset.CoreV1().ReplicationControllers(p.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("replication controller %s of pod %s not found", ref.Name, p.Name)
+		}
+		w.selector = labels.SelectorFromSet(r.Spec.Selector)
+		return w, nil
+	default:
+		// other controllers, e.g. custom resources, own their pods directly
+		return w, nil
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	w.selector = s
+	return w, nil
+}
+
+// GetPodsFromWorkload returns all pods of a workload.
+func (k *KubeClient) GetPodsFromWorkload(w *Workload) ([]v1.Pod, error) {
+	if w.Kind == KindPod {
+		p, err := k.GetPod(w.Name, w.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Pod{*p}, nil
+	}
+
+	options := metav1.ListOptions{}
+	if w.selector != nil {
+		options.LabelSelector = w.selector.String()
+	}
+	l, err := k.clientset.CoreV1().Pods(w.Namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+	if w.selector != nil {
+		return l.Items, nil
+	}
+
+	owners := map[types.UID]bool{w.UID: true}
+	if w.Kind == KindCronJob {
+		jobs, err := k.clientset.BatchV1().Jobs(w.Namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range jobs.Items {
+			if o := metav1.GetControllerOf(&j); o != nil && o.UID == w.UID {
+				owners[j.UID] = true
+			}
+		}
+	}
+	var pods []v1.Pod
+	for _, p := range l.Items {
+		if o := metav1.GetControllerOf(&p); o != nil && owners[o.UID] {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
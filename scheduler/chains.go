@@ -0,0 +1,76 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/config"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+
+	// built-in middlewares
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/affinity"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/deploymentstatus"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/external"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/latency"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/nodehealth"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/nodeselector"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/resourcefit"
+	_ "github.com/telekom/k8s-edge-scheduler/scheduler/middleware/topologyspread"
+)
+
+// buildAdapters creates the filter and score phases of all plugins, followed by the adapters in the configured order.
+func buildAdapters(chain []config.Middleware) ([]middleware.Adapter, error) {
+	var entries []middleware.Entry
+	var adapters []middleware.Adapter
+	for _, m := range chain {
+		factory, ok := middleware.Lookup(m.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware %s, registered are %s", m.Name, strings.Join(middleware.Registered(), ", "))
+		}
+		p, err := factory(m.Args)
+		if err != nil {
+			return nil, err
+		}
+		if p.Filter != nil || p.Score != nil {
+			entries = append(entries, middleware.Entry{
+				Name:   m.Name,
+				Weight: m.Weight,
+				Plugin: p,
+			})
+		}
+		if p.Adapter != nil {
+			adapters = append(adapters, middleware.Named(m.Name, m.Weight, p.Adapter))
+		}
+	}
+	if len(entries) > 0 {
+		adapters = append([]middleware.Adapter{middleware.Phases(entries)}, adapters...)
+	}
+	return adapters, nil
+}
+
+// buildChains creates the schedule and deschedule middleware chains from the configuration.
+func (s *Scheduler) buildChains(c *config.Config) (*chains, error) {
+	schedule, err := buildAdapters(c.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("schedule chain: %s", err.Error())
+	}
+	deschedule, err := buildAdapters(c.Deschedule)
+	if err != nil {
+		return nil, fmt.Errorf("deschedule chain: %s", err.Error())
+	}
+
+	return &chains{
+		scheduleM:   middleware.Adapt(s.bindPod, schedule...),
+		descheduleM: middleware.Adapt(s.evictPod, deschedule...),
+		// same as scheduleM, but without binding
+		scoreM: middleware.Adapt(func(middleware.Scheduler, *middleware.Data) {}, schedule...),
+	}, nil
+}
@@ -0,0 +1,77 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Default is used if no configuration file is given.
+const Default = `
+schedule:
+  - name: nodehealth
+  - name: nodeselector
+  - name: resourcefit
+  - name: affinity
+  - name: topologyspread
+  - name: location
+  - name: deploymentstatus
+deschedule:
+  - name: nodehealth
+  - name: nodeselector
+  - name: resourcefit
+  - name: affinity
+  - name: topologyspread
+  - name: location
+  - name: deploymentstatus
+`
+
+// Config lists the middlewares of the schedule and the deschedule chain in the order they run.
+type Config struct {
+	Schedule   []Middleware `yaml:"schedule"`
+	Deschedule []Middleware `yaml:"deschedule"`
+}
+
+type Middleware struct {
+	Name   string          `yaml:"name"`
+	Weight float64         `yaml:"weight"`
+	Args   middleware.Args `yaml:"args,omitempty"`
+}
+
+func (m *Middleware) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Middleware
+	p := plain{
+		Weight: 1,
+	}
+	if err := unmarshal(&p); err != nil {
+		return err
+	}
+	*m = Middleware(p)
+	return nil
+}
+
+func Parse(b []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %s", err.Error())
+	}
+	for _, chain := range [][]Middleware{c.Schedule, c.Deschedule} {
+		for _, m := range chain {
+			if m.Name == "" {
+				return nil, fmt.Errorf("invalid configuration: middleware without name")
+			}
+			if m.Weight <= 0 {
+				return nil, fmt.Errorf("invalid configuration: weight of middleware %s must be positive", m.Name)
+			}
+		}
+	}
+	return c, nil
+}
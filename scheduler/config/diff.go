@@ -0,0 +1,77 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package config
+
+import (
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Diff compares two configurations line by line and returns the removed
+// lines prefixed with "-" and the added lines prefixed with "+".
+func Diff(old *Config, new *Config) []string {
+	a, b := lines(old), lines(new)
+
+	// longest common subsequence
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			diff = append(diff, "-"+a[i])
+			i++
+		} else {
+			diff = append(diff, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+"+b[j])
+	}
+	return diff
+}
+
+func lines(c *Config) []string {
+	if c == nil {
+		return nil
+	}
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	l := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	for i, v := range l {
+		// don't leak credentials into logs
+		if k := strings.TrimSpace(strings.SplitN(v, ":", 2)[0]); strings.HasSuffix(strings.ToLower(k), "password") {
+			l[i] = strings.SplitN(v, ":", 2)[0] + ": '***'"
+		}
+	}
+	return l
+}
@@ -8,37 +8,58 @@
 package scheduler
 
 import (
+	"fmt"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/nodehealth"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (s *Scheduler) deschedule() {
-	pods, err := s.kube.GetClientset().CoreV1().Pods(s.namespace).List(metav1.ListOptions{})
+	pods, err := s.listPods()
 	if err != nil {
 		log.Warn(err.Error())
 	}
-	for _, p := range pods.Items {
-		if p.Status.Phase == "Running" && p.Spec.SchedulerName == s.name {
-			d, err := s.kube.GetDeploymentFromPod(&p)
+	for _, p := range pods {
+		if p.Status.Phase == "Running" && (p.Spec.SchedulerName == s.name || s.shadow) {
+			w, err := s.kube.GetWorkloadFromPod(&p)
 			if err != nil {
-				log.Warnf("pod %s belongs to no deployment", p.Name)
+				log.Warnf("no workload for pod %s/%s: %s", p.Namespace, p.Name, err.Error())
+				continue
+			}
+			// nothing would replace the pod on another node
+			if !w.Movable() {
+				continue
 			}
-			s.descheduleM(s, &middleware.Data{
-				Pod:        &p,
-				Deployment: d,
-				Prio:       priomap.NewNodePrioMap(s.nodes.Keys()),
+			s.getChains().descheduleM(s, &middleware.Data{
+				Pod:      &p,
+				Workload: w,
+				Prio:     priomap.NewNodePrioMap(s.nodes.Keys()),
 			})
 		}
 	}
 }
 
 func (s *Scheduler) evictPod(_ middleware.Scheduler, d *middleware.Data) {
-	node := d.Prio.(*priomap.NodePrioMap).Max()
+	prio := d.Prio.(*priomap.NodePrioMap)
+	node := prio.Max()
 	p, _ := d.Prio.Get(node)
+	// scores of equal nodes don't justify an eviction
+	current, err := d.Prio.Get(d.Pod.Spec.NodeName)
+
+	// pods on nodes which became unusable, e.g. unreachable, move regardless of the score
+	reason, from := metrics.EvictionPlacement, d.Pod.Spec.NodeName
+	if r, ok := prio.Disabled()[d.Pod.Spec.NodeName]; ok && r.Component == nodehealth.Name {
+		reason = metrics.EvictionNodeDisabled
+		from = fmt.Sprintf("%s (%s: %s)", d.Pod.Spec.NodeName, r.Component, r.Message)
+	}
 
-	if node == "" || d.Pod.Spec.NodeName == node || p == 0 {
+	if node == "" || d.Pod.Spec.NodeName == node ||
+		(reason == metrics.EvictionPlacement && (p == 0 || (err == nil && p <= current))) {
 		log.Debugf("pod %s is placed at the right node", d.Pod.Name)
 		return
 	}
@@ -55,12 +76,26 @@ func (s *Scheduler) evictPod(_ middleware.Scheduler, d *middleware.Data) {
 		DeleteOptions: &metav1.DeleteOptions{},
 	}
 
-	err := s.kube.GetClientset().Policy().Evictions(d.Pod.Namespace).Evict(e)
+	decision := s.newDecision(actionEvict, d, node)
+	defer s.history.Add(decision)
+
+	if s.dryRun {
+		log.Infof("dry run: would evict pod %s/%s from node %s in favor of node %s with score %d", d.Pod.Namespace, d.Pod.Name, from, node, p)
+		metrics.Evictions.WithLabelValues(reason, metrics.ResultDryRun).Inc()
+		return
+	}
+
+	err = s.kube.GetClientset().Policy().Evictions(d.Pod.Namespace).Evict(e)
 	if err != nil {
 		log.Warnf("cloud no evict pod %s: %s", d.Pod.Name, err.Error())
+		s.recorder.Eventf(d.Pod, v1.EventTypeWarning, reasonFailedEviction, "Eviction from node %s in favor of node %s with score %d failed: %s", from, node, p, err.Error())
+		decision.Error = err.Error()
+		metrics.Evictions.WithLabelValues(reason, metrics.ResultError).Inc()
 		return
 	}
-	log.Infof("evict pod %s from node %s", d.Pod.Name, d.Pod.Spec.NodeName)
+	log.Infof("evict pod %s from node %s", d.Pod.Name, from)
+	metrics.Evictions.WithLabelValues(reason, metrics.ResultEvicted).Inc()
+	s.recorder.Eventf(d.Pod, v1.EventTypeNormal, reasonEvicted, "Evicted from node %s in favor of node %s with score %d", from, node, p)
 
-	s.decisions.Set(d.Deployment.Name, d)
+	s.decisions.Set(d.Workload.Key(), d)
 }
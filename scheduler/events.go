@@ -0,0 +1,98 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	reasonScheduled        = "Scheduled"
+	reasonFailedScheduling = "FailedScheduling"
+	reasonEvicted          = "Evicted"
+	reasonFailedEviction   = "FailedEviction"
+)
+
+func (s *Scheduler) newEventRecorder() record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedv1.EventSinkImpl{
+		Interface: s.kube.GetClientset().CoreV1().Events(metav1.NamespaceAll),
+	})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: s.name})
+}
+
+// unschedulableMessage summarizes which middleware disabled which nodes.
+func unschedulableMessage(prio *priomap.NodePrioMap) string {
+	nodes := prio.Keys()
+	if len(nodes) == 0 {
+		return "no nodes available to schedule pods"
+	}
+
+	byReason := make(map[string][]string)
+	for node, r := range prio.Disabled() {
+		k := r.Message
+		if r.Component != "" {
+			k = fmt.Sprintf("%s: %s", r.Component, r.Message)
+		}
+		byReason[k] = append(byReason[k], node)
+	}
+
+	var reasons []string
+	for r, n := range byReason {
+		sort.Strings(n)
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", r, strings.Join(n, ", ")))
+	}
+	sort.Strings(reasons)
+
+	return fmt.Sprintf("0/%d nodes are available: %s", len(nodes), strings.Join(reasons, "; "))
+}
+
+// setUnschedulable sets the PodScheduled condition of the pod to false.
+func (s *Scheduler) setUnschedulable(pod *v1.Pod, message string) {
+	p := pod.DeepCopy()
+	now := metav1.Now()
+	condition := v1.PodCondition{
+		Type:               v1.PodScheduled,
+		Status:             v1.ConditionFalse,
+		Reason:             v1.PodReasonUnschedulable,
+		Message:            message,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	}
+
+	found := false
+	for i, c := range p.Status.Conditions {
+		if c.Type != v1.PodScheduled {
+			continue
+		}
+		if c.Status == condition.Status && c.Reason == condition.Reason && c.Message == condition.Message {
+			return
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+		p.Status.Conditions[i] = condition
+		found = true
+	}
+	if !found {
+		p.Status.Conditions = append(p.Status.Conditions, condition)
+	}
+
+	if _, err := s.kube.GetClientset().CoreV1().Pods(p.Namespace).UpdateStatus(p); err != nil {
+		log.Warnf("could not update condition of pod %s/%s: %s", p.Namespace, p.Name, err.Error())
+	}
+}
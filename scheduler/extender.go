@@ -0,0 +1,270 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/telekom/k8s-edge-scheduler/cache"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxExtenderPriority is the highest priority kube-scheduler accepts from an extender.
+const MaxExtenderPriority = 10
+
+// The types mirror the scheduler extender api of kube-scheduler, which encodes the field names as they are.
+
+// ExtenderArgs holds the pod and either the nodes or, for a node cache capable extender, the node names.
+type ExtenderArgs struct {
+	Pod       *v1.Pod
+	Nodes     *v1.NodeList
+	NodeNames *[]string
+}
+
+// ExtenderFilterResult lists the nodes the pod can run on and the reason for all others.
+type ExtenderFilterResult struct {
+	Nodes       *v1.NodeList
+	NodeNames   *[]string
+	FailedNodes map[string]string
+	Error       string
+}
+
+// HostPriority is the priority of a node, from 0 to MaxExtenderPriority.
+type HostPriority struct {
+	Host  string
+	Score int
+}
+
+// ExtenderPreemptionArgs lists the pods kube-scheduler would preempt on every node.
+type ExtenderPreemptionArgs struct {
+	Pod                   *v1.Pod
+	NodeNameToVictims     map[string]*Victims
+	NodeNameToMetaVictims map[string]*MetaVictims
+}
+
+type Victims struct {
+	Pods             []*v1.Pod
+	NumPDBViolations int
+}
+
+type MetaVictims struct {
+	Pods             []*MetaPod
+	NumPDBViolations int
+}
+
+type MetaPod struct {
+	UID string
+}
+
+// ExtenderPreemptionResult lists the nodes which are still candidates for preemption.
+type ExtenderPreemptionResult struct {
+	NodeNameToMetaVictims map[string]*MetaVictims
+}
+
+// extenderScheduler runs the middlewares on the nodes of an extender request instead of the node cache.
+type extenderScheduler struct {
+	*Scheduler
+	nodes *cache.Cache
+}
+
+func (e *extenderScheduler) GetNodes() *cache.Cache {
+	return e.nodes
+}
+
+// evaluate runs the schedule chain without binding for the given nodes,
+// nodes only given by name are taken from the node cache or the api server.
+func (s *Scheduler) evaluate(pod *v1.Pod, nodes []v1.Node, names []string) (*priomap.NodePrioMap, error) {
+	if pod == nil {
+		return nil, fmt.Errorf("pod is missing")
+	}
+	c := cache.NewCache()
+	c.Timeout = 0
+	for i := range nodes {
+		c.Set(nodes[i].Name, &nodes[i])
+		names = append(names, nodes[i].Name)
+	}
+	for _, n := range names {
+		if _, ok := c.Get(n); ok {
+			continue
+		}
+		if o, ok := s.nodes.Get(n); ok {
+			c.Set(n, o)
+			continue
+		}
+		node, err := s.kube.GetClientset().CoreV1().Nodes().Get(n, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		c.Set(n, node)
+	}
+
+	data := &middleware.Data{
+		Pod:  pod,
+		Prio: priomap.NewNodePrioMap(names),
+	}
+	w, err := s.kube.GetWorkloadFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+	data.Workload = w
+	s.getChains().scoreM(&extenderScheduler{Scheduler: s, nodes: c}, data)
+	return data.Prio.(*priomap.NodePrioMap), nil
+}
+
+// cachesSynced reports whether the caches the middlewares read are synced and answers
+// with an error otherwise. In extender mode every replica syncs them, otherwise only the leader.
+func (s *Scheduler) cachesSynced(w http.ResponseWriter) bool {
+	state := s.getState()
+	if state == stateLeading || s.extender && state == stateStandby {
+		return true
+	}
+	http.Error(w, "scheduler caches are not synced", http.StatusServiceUnavailable)
+	return false
+}
+
+func extenderNodes(args *ExtenderArgs) ([]v1.Node, []string) {
+	var nodes []v1.Node
+	var names []string
+	if args.Nodes != nil {
+		nodes = args.Nodes.Items
+	}
+	if args.NodeNames != nil {
+		names = *args.NodeNames
+	}
+	return nodes, names
+}
+
+func (s *Scheduler) handleExtenderFilter(w http.ResponseWriter, r *http.Request) {
+	if !s.cachesSynced(w) {
+		return
+	}
+	args := &ExtenderArgs{}
+	if !decodeJSON(w, r, args) {
+		return
+	}
+	nodes, names := extenderNodes(args)
+	result := &ExtenderFilterResult{
+		FailedNodes: make(map[string]string),
+	}
+
+	prio, err := s.evaluate(args.Pod, nodes, names)
+	if err != nil {
+		result.Error = err.Error()
+		writeJSON(w, result)
+		return
+	}
+	disabled := prio.Disabled()
+	for n, reason := range disabled {
+		result.FailedNodes[n] = reason.Component + ": " + reason.Message
+	}
+
+	if args.Nodes != nil {
+		result.Nodes = &v1.NodeList{}
+		for _, n := range nodes {
+			if _, ok := disabled[n.Name]; !ok {
+				result.Nodes.Items = append(result.Nodes.Items, n)
+			}
+		}
+	} else {
+		fit := []string{}
+		for _, n := range names {
+			if _, ok := disabled[n]; !ok {
+				fit = append(fit, n)
+			}
+		}
+		result.NodeNames = &fit
+	}
+	writeJSON(w, result)
+}
+
+func (s *Scheduler) handleExtenderPrioritize(w http.ResponseWriter, r *http.Request) {
+	if !s.cachesSynced(w) {
+		return
+	}
+	args := &ExtenderArgs{}
+	if !decodeJSON(w, r, args) {
+		return
+	}
+	nodes, names := extenderNodes(args)
+
+	prio, err := s.evaluate(args.Pod, nodes, names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	priorities := []HostPriority{}
+	for _, p := range prio.List() {
+		score := 0
+		if p.Value > 0 {
+			score = (p.Value*MaxExtenderPriority + middleware.MaxScore/2) / middleware.MaxScore
+		}
+		priorities = append(priorities, HostPriority{
+			Host:  p.Key,
+			Score: score,
+		})
+	}
+	writeJSON(w, priorities)
+}
+
+// handleExtenderPreempt keeps the victims kube-scheduler chose, but drops the nodes the middlewares reject.
+func (s *Scheduler) handleExtenderPreempt(w http.ResponseWriter, r *http.Request) {
+	if !s.cachesSynced(w) {
+		return
+	}
+	args := &ExtenderPreemptionArgs{}
+	if !decodeJSON(w, r, args) {
+		return
+	}
+
+	victims := args.NodeNameToMetaVictims
+	if victims == nil {
+		victims = make(map[string]*MetaVictims)
+		for n, v := range args.NodeNameToVictims {
+			m := &MetaVictims{
+				NumPDBViolations: v.NumPDBViolations,
+			}
+			for _, p := range v.Pods {
+				m.Pods = append(m.Pods, &MetaPod{UID: string(p.UID)})
+			}
+			victims[n] = m
+		}
+	}
+	var names []string
+	for n := range victims {
+		names = append(names, n)
+	}
+
+	prio, err := s.evaluate(args.Pod, nil, names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for n := range prio.Disabled() {
+		delete(victims, n)
+	}
+	writeJSON(w, &ExtenderPreemptionResult{
+		NodeNameToMetaVictims: victims,
+	})
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+)
+
+const (
+	actionBind          = "bind"
+	actionEvict         = "evict"
+	actionUnschedulable = "unschedulable"
+	actionCompare       = "compare"
+)
+
+// Decision records a placement of the scheduler or descheduler together with
+// the score breakdown of every node.
+type Decision struct {
+	Time     time.Time                      `json:"time"`
+	Action   string                         `json:"action"`
+	Pod      string                         `json:"pod"`
+	Workload string                         `json:"workload,omitempty"`
+	From     string                         `json:"from,omitempty"`
+	Node     string                         `json:"node,omitempty"`
+	Actual   string                         `json:"actual,omitempty"`
+	Score    int                            `json:"score"`
+	DryRun   bool                           `json:"dryRun,omitempty"`
+	Error    string                         `json:"error,omitempty"`
+	Nodes    map[string]priomap.Explanation `json:"nodes"`
+}
+
+func (s *Scheduler) newDecision(action string, d *middleware.Data, node string) *Decision {
+	prio := d.Prio.(*priomap.NodePrioMap)
+	score, _ := prio.Get(node)
+	decision := &Decision{
+		Time:   time.Now(),
+		Action: action,
+		Pod:    d.Pod.Namespace + "/" + d.Pod.Name,
+		Node:   node,
+		Score:  score,
+		DryRun: s.dryRun,
+		Nodes:  prio.Explain(),
+	}
+	if d.Workload != nil {
+		decision.Workload = d.Workload.Key()
+	}
+	if action == actionEvict {
+		decision.From = d.Pod.Spec.NodeName
+	}
+	return decision
+}
+
+// history keeps the most recent decisions.
+type history struct {
+	mutex     sync.Mutex
+	size      int
+	decisions []*Decision
+}
+
+func newHistory(size int) *history {
+	return &history{
+		size: size,
+	}
+}
+
+func (h *history) Add(d *Decision) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.size <= 0 {
+		return
+	}
+	h.decisions = append(h.decisions, d)
+	if len(h.decisions) > h.size {
+		h.decisions = h.decisions[len(h.decisions)-h.size:]
+	}
+}
+
+// List returns the decisions, newest first.
+func (h *history) List() []*Decision {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	l := make([]*Decision, len(h.decisions))
+	for i, d := range h.decisions {
+		l[len(h.decisions)-1-i] = d
+	}
+	return l
+}
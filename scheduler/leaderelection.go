@@ -0,0 +1,68 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+type electionConfig struct {
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	namespace     string
+	identity      string
+}
+
+// runLeaderElection blocks until this replica becomes leader and then calls run.
+// Losing the lease terminates the process, so a restarted replica joins as standby.
+func (s *Scheduler) runLeaderElection(run func(stop <-chan struct{})) {
+	lock, err := resourcelock.New(
+		resourcelock.ConfigMapsResourceLock,
+		s.election.namespace,
+		s.name,
+		s.kube.GetClientset().CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      s.election.identity,
+			EventRecorder: s.recorder,
+		},
+	)
+	if err != nil {
+		log.Fatalf("could not create leader election lock: %s", err.Error())
+	}
+
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: s.election.leaseDuration,
+		RenewDeadline: s.election.renewDeadline,
+		RetryPeriod:   s.election.retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(stop <-chan struct{}) {
+				log.Infof("%s is leading as %s", s.election.identity, s.name)
+				run(stop)
+			},
+			OnStoppedLeading: func() {
+				log.Fatalf("%s lost leadership", s.election.identity)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != s.election.identity {
+					log.Infof("%s is standby, current leader is %s", s.election.identity, identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("could not create leader elector: %s", err.Error())
+	}
+
+	log.Infof("%s tries to acquire leader lock %s/%s", s.election.identity, s.election.namespace, s.name)
+	le.Run()
+}
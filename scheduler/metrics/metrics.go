@@ -0,0 +1,115 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "edge_scheduler"
+
+const (
+	ResultScheduled     = "scheduled"
+	ResultUnschedulable = "unschedulable"
+	ResultError         = "error"
+	ResultDryRun        = "dry_run"
+	ResultEvicted       = "evicted"
+
+	EvictionPlacement    = "placement"
+	EvictionNodeDisabled = "node_disabled"
+)
+
+var (
+	SchedulingAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "schedule_attempts_total",
+		Help:      "Number of attempts to schedule pods, by result.",
+	}, []string{"result"})
+
+	BindingDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "binding_duration_seconds",
+		Help:      "Latency of binding pods to nodes.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	})
+
+	E2eSchedulingDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "e2e_scheduling_duration_seconds",
+		Help:      "Time from pod creation until the pod is bound to a node.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	})
+
+	MiddlewareDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "middleware_duration_seconds",
+		Help:      "Execution time of a single middleware, by middleware.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 18),
+	}, []string{"middleware"})
+
+	Evictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evictions_total",
+		Help:      "Number of pods evicted by the descheduler, by reason and result.",
+	}, []string{"reason", "result"})
+
+	ShadowPlacements = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shadow_placements_total",
+		Help:      "Number of pods placed by another scheduler in shadow mode, by whether the placement matches.",
+	}, []string{"match"})
+
+	InfluxQueryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "influx_query_duration_seconds",
+		Help:      "Latency of InfluxDB queries.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	})
+
+	InfluxQueryErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "influx_query_errors_total",
+		Help:      "Number of failed InfluxDB queries.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(
+		SchedulingAttempts,
+		BindingDuration,
+		E2eSchedulingDuration,
+		MiddlewareDuration,
+		Evictions,
+		ShadowPlacements,
+		InfluxQueryDuration,
+		InfluxQueryErrors,
+	)
+}
+
+// RegisterDecisionCache exposes the number of cached descheduling decisions.
+func RegisterDecisionCache(size func() int) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "decision_cache_size",
+		Help:      "Number of descheduling decisions waiting for the replacement pod.",
+	}, func() float64 {
+		return float64(size())
+	}))
+}
+
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,148 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package affinity
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const name = "affinity"
+
+var (
+	log *logrus.Entry
+)
+
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	return &middleware.Plugin{
+		Filter: Filter,
+		Score:  Score,
+	}, nil
+}
+
+// Filter disables nodes which don't match the required node affinity or pod (anti) affinity.
+func Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	log = s.Log(name)
+	disabled := make(map[string]string)
+	t := newTopology(s, d.Pod)
+	for _, n := range nodes {
+		if reason := matchRequiredNodeAffinity(d.Pod, n); reason != "" {
+			disabled[n.Name] = reason
+		} else if reason := t.matchRequiredPodAffinity(d.Pod, n); reason != "" {
+			disabled[n.Name] = reason
+		}
+	}
+	return disabled
+}
+
+// Score rates nodes by the weights of the preferred node affinity and pod (anti) affinity terms they match.
+func Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	log = s.Log(name)
+	scores := make(map[string]int)
+	if d.Pod.Spec.Affinity == nil {
+		return scores
+	}
+	t := newTopology(s, d.Pod)
+
+	weights := make(map[string]int)
+	min, max := 0, 0
+	for i, n := range nodes {
+		w := preferredNodeAffinityWeight(d.Pod, n) + t.preferredPodAffinityWeight(d.Pod, n)
+		weights[n.Name] = w
+		if i == 0 || w < min {
+			min = w
+		}
+		if i == 0 || w > max {
+			max = w
+		}
+	}
+	for n, w := range weights {
+		if max > min {
+			scores[n] = (w - min) * middleware.MaxScore / (max - min)
+		} else if max > 0 {
+			scores[n] = middleware.MaxScore
+		}
+	}
+	return scores
+}
+
+func matchRequiredNodeAffinity(p *v1.Pod, n *v1.Node) string {
+	if p.Spec.Affinity == nil || p.Spec.Affinity.NodeAffinity == nil ||
+		p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	// terms are ORed
+	for _, term := range p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if matchNodeSelectorTerm(term, n) {
+			return ""
+		}
+	}
+	return "node affinity does not match"
+}
+
+func preferredNodeAffinityWeight(p *v1.Pod, n *v1.Node) int {
+	if p.Spec.Affinity.NodeAffinity == nil {
+		return 0
+	}
+	w := 0
+	for _, term := range p.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if matchNodeSelectorTerm(term.Preference, n) {
+			w += int(term.Weight)
+		}
+	}
+	return w
+}
+
+// matchNodeSelectorTerm checks all requirements of a term, a term without requirements matches no node.
+func matchNodeSelectorTerm(term v1.NodeSelectorTerm, n *v1.Node) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, r := range term.MatchExpressions {
+		if !matchNodeSelectorRequirement(r, labels.Set(n.Labels)) {
+			return false
+		}
+	}
+	for _, r := range term.MatchFields {
+		// metadata.name is the only supported field
+		if r.Key != "metadata.name" || !matchNodeSelectorRequirement(r, labels.Set{r.Key: n.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+var operators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func matchNodeSelectorRequirement(r v1.NodeSelectorRequirement, l labels.Set) bool {
+	op, ok := operators[r.Operator]
+	if !ok {
+		log.Warnf("unknown node selector operator %s", r.Operator)
+		return false
+	}
+	req, err := labels.NewRequirement(r.Key, op, r.Values)
+	if err != nil {
+		log.Warnf("invalid node selector requirement: %s", err.Error())
+		return false
+	}
+	return req.Matches(l)
+}
@@ -0,0 +1,164 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package affinity
+
+import (
+	"fmt"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// topology knows the pods of all nodes and the topology domains of the nodes.
+type topology struct {
+	s     middleware.Scheduler
+	nodes map[string]*v1.Node
+	pods  map[string][]*v1.Pod
+}
+
+func newTopology(s middleware.Scheduler, pod *v1.Pod) *topology {
+	t := &topology{
+		s:     s,
+		nodes: make(map[string]*v1.Node),
+		pods:  make(map[string][]*v1.Pod),
+	}
+	for _, n := range middleware.Nodes(s) {
+		t.nodes[n.Name] = n
+		t.pods[n.Name] = middleware.OtherPods(s, n.Name, pod)
+	}
+	return t
+}
+
+// domain returns the value of the topology key of a node.
+func (t *topology) domain(n *v1.Node, key string) (string, bool) {
+	return middleware.TopologyValue(t.s, n, key)
+}
+
+// podsInDomain returns the pods on all nodes in the same topology domain as the node.
+func (t *topology) podsInDomain(n *v1.Node, key string) []*v1.Pod {
+	v, ok := t.domain(n, key)
+	if !ok {
+		return nil
+	}
+	var pods []*v1.Pod
+	for k, o := range t.nodes {
+		if w, ok := t.domain(o, key); ok && w == v {
+			pods = append(pods, t.pods[k]...)
+		}
+	}
+	return pods
+}
+
+func (t *topology) matchRequiredPodAffinity(p *v1.Pod, n *v1.Node) string {
+	a := p.Spec.Affinity
+	if a != nil && a.PodAffinity != nil {
+		for _, term := range a.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if !t.anyMatches(p, term, t.podsInDomain(n, term.TopologyKey)) && !t.isFirstOfGroup(p, term) {
+				return fmt.Sprintf("pod affinity %s does not match", describeTerm(term))
+			}
+		}
+	}
+	if a != nil && a.PodAntiAffinity != nil {
+		for _, term := range a.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if t.anyMatches(p, term, t.podsInDomain(n, term.TopologyKey)) {
+				return fmt.Sprintf("pod anti affinity %s does not match", describeTerm(term))
+			}
+		}
+	}
+
+	// anti affinity of the pods which are already placed
+	for k, pods := range t.pods {
+		for _, o := range pods {
+			if o.Spec.Affinity == nil || o.Spec.Affinity.PodAntiAffinity == nil {
+				continue
+			}
+			for _, term := range o.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				if !matchesTerm(o, term, p) {
+					continue
+				}
+				v, ok := t.domain(t.nodes[k], term.TopologyKey)
+				if w, found := t.domain(n, term.TopologyKey); ok && found && v == w {
+					return fmt.Sprintf("pod anti affinity of %s/%s does not match", o.Namespace, o.Name)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (t *topology) preferredPodAffinityWeight(p *v1.Pod, n *v1.Node) int {
+	w := 0
+	if p.Spec.Affinity.PodAffinity != nil {
+		for _, term := range p.Spec.Affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			if t.anyMatches(p, term.PodAffinityTerm, t.podsInDomain(n, term.PodAffinityTerm.TopologyKey)) {
+				w += int(term.Weight)
+			}
+		}
+	}
+	if p.Spec.Affinity.PodAntiAffinity != nil {
+		for _, term := range p.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			if t.anyMatches(p, term.PodAffinityTerm, t.podsInDomain(n, term.PodAffinityTerm.TopologyKey)) {
+				w -= int(term.Weight)
+			}
+		}
+	}
+	return w
+}
+
+// isFirstOfGroup allows the first pod of a group, which matches its own affinity term, on any node.
+func (t *topology) isFirstOfGroup(p *v1.Pod, term v1.PodAffinityTerm) bool {
+	if !matchesTerm(p, term, p) {
+		return false
+	}
+	for _, pods := range t.pods {
+		if t.anyMatches(p, term, pods) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *topology) anyMatches(p *v1.Pod, term v1.PodAffinityTerm, pods []*v1.Pod) bool {
+	for _, o := range pods {
+		if matchesTerm(p, term, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTerm checks if the term of pod p selects pod o.
+func matchesTerm(p *v1.Pod, term v1.PodAffinityTerm, o *v1.Pod) bool {
+	namespaces := term.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{p.Namespace}
+	}
+	found := false
+	for _, n := range namespaces {
+		if n == o.Namespace {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		log.Warnf("invalid label selector of pod %s/%s: %s", p.Namespace, p.Name, err.Error())
+		return false
+	}
+	return term.LabelSelector != nil && selector.Matches(labels.Set(o.Labels))
+}
+
+func describeTerm(term v1.PodAffinityTerm) string {
+	return fmt.Sprintf("%s in %s", metav1.FormatLabelSelector(term.LabelSelector), term.TopologyKey)
+}
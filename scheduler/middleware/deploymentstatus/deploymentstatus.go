@@ -8,7 +8,8 @@
 package deploymentstatus
 
 import (
-	"github.com/namsral/flag"
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
 	v1 "k8s.io/api/core/v1"
@@ -17,48 +18,76 @@ import (
 const name = "deploymentstatus"
 
 var (
-	log     *logrus.Entry
-	maxPods int
+	log *logrus.Entry
 )
 
+type Config struct {
+	MaxPods int `yaml:"maxPods"`
+}
+
 func init() {
-	flag.IntVar(&maxPods, "maxPods", 2, "max pods per node")
+	middleware.Register(name, New)
 }
 
-func DeploymentStatus(m middleware.Middleware) middleware.Middleware {
-	return func(s middleware.Scheduler, d *middleware.Data) {
-		log = s.Log(name)
-		defer m(s, d)
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	c := Config{
+		MaxPods: 2,
+	}
+	if err := args.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid %s settings: %s", name, err.Error())
+	}
+	return &middleware.Plugin{
+		Filter: c.Filter,
+		Score:  c.Score,
+	}, nil
+}
 
-		podCount, err := getPodCountByNode(s, d)
-		if err != nil {
-			log.Warn(err.Error())
-			return
+// Filter disables nodes which run already MaxPods pods of the workload.
+func (cfg Config) Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	log = s.Log(name)
+	podCount, err := getPodCountByNode(s, d, nodes)
+	if err != nil {
+		log.Warn(err.Error())
+		return nil
+	}
+
+	disabled := make(map[string]string)
+	for n, c := range podCount {
+		if c >= cfg.MaxPods {
+			disabled[n] = fmt.Sprintf("node runs already %d pods of %s", c, d.Workload)
 		}
+	}
+	return disabled
+}
 
-		for _, n := range d.Prio.Keys() {
-			if c, ok := podCount[n]; ok && c > 0 {
-				log.Debugf("node %s runs %d other pods of deployment %s", n, c, d.Deployment.Name)
-				if c < maxPods {
-					o, _ := d.Prio.Get(n)
-					d.Prio.Set(n, o/(c+1))
-				} else {
-					d.Prio.Disable(n)
-				}
-			}
+// Score prefers nodes with less pods of the workload.
+func (cfg Config) Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	log = s.Log(name)
+	podCount, err := getPodCountByNode(s, d, nodes)
+	if err != nil {
+		log.Warn(err.Error())
+		return nil
+	}
+
+	scores := make(map[string]int)
+	for n, c := range podCount {
+		if c > 0 {
+			log.Debugf("node %s runs %d other pods of %s", n, c, d.Workload)
 		}
+		scores[n] = middleware.MaxScore / (c + 1)
 	}
+	return scores
 }
 
-func getPodCountByNode(s middleware.Scheduler, d *middleware.Data) (map[string]int, error) {
-	other, err := s.GetKube().GetPodsFromDeployment(d.Deployment)
+func getPodCountByNode(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) (map[string]int, error) {
+	other, err := s.GetKube().GetPodsFromWorkload(d.Workload)
 	if err != nil {
 		return nil, err
 	}
 
 	r := make(map[string]int)
-	for _, n := range d.Prio.Keys() {
-		r[n] = 0
+	for _, n := range nodes {
+		r[n.Name] = 0
 	}
 	for _, p := range other {
 		if d.Pod.Name != p.Name && isReady(&p) {
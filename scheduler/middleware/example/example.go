@@ -18,6 +18,18 @@ var (
 	log *logrus.Entry
 )
 
+// register the middleware, so it can be used by name in the scheduler configuration
+// once the package is imported, e.g. with a blank import next to the scheduler
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	return &middleware.Plugin{
+		Adapter: Example,
+	}, nil
+}
+
 func Example(m middleware.Middleware) middleware.Middleware {
 	return func(s middleware.Scheduler, d *middleware.Data) {
 		log = s.Log(name)
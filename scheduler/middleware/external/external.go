@@ -0,0 +1,153 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	name = "external"
+
+	// FailurePolicyIgnore keeps all nodes and gives them the same score if the service fails.
+	FailurePolicyIgnore = "Ignore"
+	// FailurePolicyFail disables all nodes if the service fails.
+	FailurePolicyFail = "Fail"
+)
+
+var (
+	log *logrus.Entry
+)
+
+// Config of a scoring service. The scheduler posts a Request to the URL and expects a Response.
+type Config struct {
+	URL           string        `yaml:"url"`
+	Timeout       time.Duration `yaml:"timeout"`
+	FailurePolicy string        `yaml:"failurePolicy"`
+}
+
+// Request lists the candidate nodes for a pod.
+type Request struct {
+	Pod      *v1.Pod              `json:"pod"`
+	Workload *kubeclient.Workload `json:"workload"`
+	Nodes    []string             `json:"nodes"`
+}
+
+// Response disables nodes with a reason and rates the others from 0 to 100.
+type Response struct {
+	Disabled map[string]string `json:"disabled,omitempty"`
+	Scores   map[string]int    `json:"scores,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+type service struct {
+	Config
+	key    string
+	client *http.Client
+}
+
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	c := Config{
+		Timeout:       5 * time.Second,
+		FailurePolicy: FailurePolicyIgnore,
+	}
+	if err := args.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid %s settings: %s", name, err.Error())
+	}
+	if c.URL == "" {
+		return nil, fmt.Errorf("invalid %s settings: url is missing", name)
+	}
+	if c.FailurePolicy != FailurePolicyIgnore && c.FailurePolicy != FailurePolicyFail {
+		return nil, fmt.Errorf("invalid %s settings: failurePolicy must be %s or %s", name, FailurePolicyIgnore, FailurePolicyFail)
+	}
+
+	s := &service{
+		Config: c,
+		// several services can be configured, so the response is stored per url
+		key:    name + "/" + c.URL,
+		client: &http.Client{Timeout: c.Timeout},
+	}
+	return &middleware.Plugin{
+		Filter: s.Filter,
+		Score:  s.Score,
+	}, nil
+}
+
+// Filter asks the service once per run and disables the nodes it rejects.
+func (e *service) Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	log = s.Log(name)
+	r, err := e.call(d, nodes)
+	if err != nil {
+		log.Warnf("scoring service %s failed: %s", e.URL, err.Error())
+		if e.FailurePolicy == FailurePolicyIgnore {
+			return nil
+		}
+		disabled := make(map[string]string)
+		for _, n := range nodes {
+			disabled[n.Name] = fmt.Sprintf("scoring service failed: %s", err.Error())
+		}
+		return disabled
+	}
+	d.Store(e.key, r)
+	return r.Disabled
+}
+
+// Score returns the scores the service sent during the filter phase.
+func (e *service) Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	if r, ok := d.Load(e.key); ok {
+		return r.(*Response).Scores
+	}
+	return nil
+}
+
+func (e *service) call(d *middleware.Data, nodes []*v1.Node) (*Response, error) {
+	req := &Request{
+		Pod:      d.Pod,
+		Workload: d.Workload,
+	}
+	for _, n := range nodes {
+		req.Nodes = append(req.Nodes, n.Name)
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := e.client.Post(e.URL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return nil, fmt.Errorf("status %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+
+	r := &Response{}
+	if err := json.NewDecoder(res.Body).Decode(r); err != nil {
+		return nil, err
+	}
+	if r.Error != "" {
+		return nil, fmt.Errorf("%s", r.Error)
+	}
+	return r, nil
+}
@@ -0,0 +1,224 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/cache"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var nodeNames = []string{"node-1", "node-2", "node-3"}
+
+// scheduler knows the nodes and nothing else.
+type scheduler struct {
+	nodes *cache.Cache
+}
+
+func newScheduler() *scheduler {
+	s := &scheduler{nodes: cache.NewCache()}
+	s.nodes.Timeout = 0
+	for _, n := range nodeNames {
+		s.nodes.Set(n, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: n}})
+	}
+	return s
+}
+
+func (s *scheduler) GetKube() middleware.KubernetesClient { return nil }
+func (s *scheduler) GetNodes() *cache.Cache               { return s.nodes }
+func (s *scheduler) GetNodePods(node string) []*v1.Pod    { return nil }
+func (s *scheduler) GetNamespaces() []string              { return nil }
+func (s *scheduler) Log(component string) *logrus.Entry {
+	l := logrus.New()
+	l.Out = ioutil.Discard
+	return l.WithField("component", component)
+}
+
+// run runs the middleware with the given settings against a stub service and returns the node priorities.
+func run(t *testing.T, handler http.HandlerFunc, args middleware.Args) map[string]int {
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	a := middleware.Args{"url": srv.URL}
+	for k, v := range args {
+		a[k] = v
+	}
+	p, err := New(a)
+	if err != nil {
+		t.Fatalf("could not create middleware: %s", err.Error())
+	}
+
+	d := &middleware.Data{
+		Pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}},
+		Prio: priomap.NewNodePrioMap(nodeNames),
+	}
+	middleware.Phases([]middleware.Entry{{Name: name, Weight: 1, Plugin: p}})(func(middleware.Scheduler, *middleware.Data) {})(newScheduler(), d)
+	return d.Prio.(*priomap.NodePrioMap).Map()
+}
+
+func respond(r Response) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(r)
+	}
+}
+
+func TestExternal(t *testing.T) {
+	unchanged := map[string]int{"node-1": 0, "node-2": 0, "node-3": 0}
+	failed := map[string]int{"node-1": -1, "node-2": -1, "node-3": -1}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		policy  string
+		want    map[string]int
+	}{
+		{
+			name: "scores and disables",
+			handler: respond(Response{
+				Disabled: map[string]string{"node-2": "too far away"},
+				Scores:   map[string]int{"node-1": 80, "node-3": 30},
+			}),
+			policy: FailurePolicyIgnore,
+			want:   map[string]int{"node-1": 80, "node-2": -1, "node-3": 30},
+		},
+		{
+			name: "scores of disabled nodes don't count",
+			handler: respond(Response{
+				Disabled: map[string]string{"node-1": "full"},
+				Scores:   map[string]int{"node-1": 100, "node-2": 50},
+			}),
+			policy: FailurePolicyFail,
+			want:   map[string]int{"node-1": -1, "node-2": 50, "node-3": 0},
+		},
+		{
+			name: "error in the response with Ignore",
+			handler: respond(Response{
+				Error:  "no data",
+				Scores: map[string]int{"node-1": 80},
+			}),
+			policy: FailurePolicyIgnore,
+			want:   unchanged,
+		},
+		{
+			name:    "error in the response with Fail",
+			handler: respond(Response{Error: "no data"}),
+			policy:  FailurePolicyFail,
+			want:    failed,
+		},
+		{
+			name: "status not ok with Ignore",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "broken", http.StatusInternalServerError)
+			},
+			policy: FailurePolicyIgnore,
+			want:   unchanged,
+		},
+		{
+			name: "status not ok with Fail",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "broken", http.StatusInternalServerError)
+			},
+			policy: FailurePolicyFail,
+			want:   failed,
+		},
+		{
+			name: "malformed response with Ignore",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"scores": {"node-1": "high"`)
+			},
+			policy: FailurePolicyIgnore,
+			want:   unchanged,
+		},
+		{
+			name: "malformed response with Fail",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"scores": {"node-1": "high"`)
+			},
+			policy: FailurePolicyFail,
+			want:   failed,
+		},
+		{
+			name: "timeout with Ignore",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				respond(Response{Scores: map[string]int{"node-1": 80}})(w, r)
+			},
+			policy: FailurePolicyIgnore,
+			want:   unchanged,
+		},
+		{
+			name: "timeout with Fail",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				respond(Response{Scores: map[string]int{"node-1": 80}})(w, r)
+			},
+			policy: FailurePolicyFail,
+			want:   failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := run(t, tt.handler, middleware.Args{
+				"timeout":       "50ms",
+				"failurePolicy": tt.policy,
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	var req Request
+	run(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with content type %s, want a JSON post", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("could not decode request: %s", err.Error())
+		}
+		respond(Response{})(w, r)
+	}, middleware.Args{})
+
+	if req.Pod == nil || req.Pod.Name != "pod" {
+		t.Errorf("got pod %v, want pod", req.Pod)
+	}
+	if len(req.Nodes) != len(nodeNames) {
+		t.Errorf("got nodes %v, want %v", req.Nodes, nodeNames)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		args middleware.Args
+	}{
+		{name: "missing url", args: middleware.Args{}},
+		{name: "unknown failure policy", args: middleware.Args{"url": "http://localhost", "failurePolicy": "Retry"}},
+		{name: "unknown setting", args: middleware.Args{"url": "http://localhost", "retries": 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.args); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package latency
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location/influxclient"
+	v1 "k8s.io/api/core/v1"
+)
+
+const name = "latency"
+
+var (
+	log *logrus.Entry
+)
+
+// Config takes the latency matrix from one source: the inline matrix, a file,
+// a config map, a LatencyMatrix resource or the round trip times measured by the
+// probe agents, the latter are read again after Refresh.
+type Config struct {
+	Matrix       Matrix               `yaml:"matrix"`
+	File         string               `yaml:"file"`
+	ConfigMap    string               `yaml:"configMap"`
+	ConfigMapKey string               `yaml:"configMapKey"`
+	Resource     string               `yaml:"resource"`
+	Probes       bool                 `yaml:"probes"`
+	ProbeDB      string               `yaml:"probeDB"`
+	ProbeWindow  string               `yaml:"probeWindow"`
+	Refresh      time.Duration        `yaml:"refresh"`
+	TimeRanges   []location.TimeRange `yaml:"timeRanges"`
+	Influx       influxclient.Config  `yaml:"influx"`
+
+	mutex  sync.Mutex
+	matrix Matrix
+	loaded time.Time
+}
+
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	c := &Config{
+		ConfigMapKey: "matrix.yml",
+		Refresh:      time.Minute,
+		ProbeDB:      "edge-latency",
+		ProbeWindow:  "5m",
+		TimeRanges: []location.TimeRange{
+			{Time: "15m"},
+			{Time: "1h"},
+			{Time: "24h"},
+		},
+		Influx: influxclient.DefaultConfig(),
+	}
+	if err := args.Decode(c); err != nil {
+		return nil, fmt.Errorf("invalid %s settings: %s", name, err.Error())
+	}
+	sources := 0
+	for _, set := range []bool{len(c.Matrix) > 0, c.File != "", c.ConfigMap != "", c.Resource != "", c.Probes} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("invalid %s settings: set one of matrix, file, configMap, resource or probes", name)
+	}
+	return &middleware.Plugin{
+		Score: c.Score,
+	}, nil
+}
+
+// Score rates nodes by the expected round trip time of the requests, which is the
+// round trip time from every source location to the location of the node weighted by
+// the share of requests from the source. The fastest node gets MaxScore, the slowest 0.
+func (c *Config) Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	log = s.Log(name)
+	scores := make(map[string]int)
+	m := c.getMatrix(s)
+	if len(m) == 0 {
+		return scores
+	}
+	shares, err := location.Config{TimeRanges: c.TimeRanges, Influx: c.Influx}.RequestShares(d.Workload)
+	if err != nil {
+		log.Warn(err.Error())
+		return scores
+	}
+	if len(shares) == 0 {
+		return scores
+	}
+
+	rtts := make(map[string]float64)
+	min, max := math.Inf(1), 0.0
+	for _, n := range nodes {
+		l, err := s.GetKube().GetLocationFromNode(n)
+		if err != nil {
+			log.Warn(err.Error())
+			continue
+		}
+		rtt, known := 0.0, 0.0
+		for source, share := range shares {
+			if v, ok := m.RTT(source, l); ok {
+				rtt += share * v
+				known += share
+			}
+		}
+		if known == 0 {
+			log.Debugf("no round trip times to location %s of node %s", l, n.Name)
+			continue
+		}
+		// requests from sources without round trip time don't count
+		rtt /= known
+		rtts[n.Name] = rtt
+		min, max = math.Min(min, rtt), math.Max(max, rtt)
+	}
+
+	for n, rtt := range rtts {
+		if max > min {
+			scores[n] = int(math.Round(float64(middleware.MaxScore) * (max - rtt) / (max - min)))
+		} else {
+			scores[n] = middleware.MaxScore
+		}
+		log.Debugf("expected round trip time of node %s is %.1f ms", n, rtt)
+	}
+	return scores
+}
+
+// getMatrix returns the cached matrix and reloads it after the refresh interval,
+// the last matrix is kept if the source fails.
+func (c *Config) getMatrix(s middleware.Scheduler) Matrix {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.matrix != nil && time.Since(c.loaded) < c.Refresh {
+		return c.matrix
+	}
+	m, err := c.loadMatrix(s)
+	if err != nil {
+		log.Warnf("could not load latency matrix: %s", err.Error())
+		return c.matrix
+	}
+	c.matrix, c.loaded = m, time.Now()
+	return m
+}
@@ -0,0 +1,149 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package latency
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location/influxclient"
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	watch "k8s.io/client-go/tools/cache"
+)
+
+const (
+	resourceGroup   = "edge-scheduler.telekom.de"
+	resourceVersion = "v1alpha1"
+	resourcePlural  = "latencymatrices"
+)
+
+// Matrix holds the round trip time in milliseconds from one location to another.
+type Matrix map[string]map[string]float64
+
+// RTT returns the round trip time between two locations, a missing direction is taken from the other one.
+func (m Matrix) RTT(from string, to string) (float64, bool) {
+	if v, ok := m[from][to]; ok {
+		return v, true
+	}
+	if v, ok := m[to][from]; ok {
+		return v, true
+	}
+	if from == to {
+		return 0, true
+	}
+	return 0, false
+}
+
+// latencyMatrix is the custom resource, see deploy/latencymatrix.yml.
+type latencyMatrix struct {
+	Spec struct {
+		RTT Matrix `json:"rtt"`
+	} `json:"spec"`
+}
+
+// loadMatrix reads the matrix from the configured source.
+func (c *Config) loadMatrix(s middleware.Scheduler) (Matrix, error) {
+	switch {
+	case c.File != "":
+		b, err := ioutil.ReadFile(c.File)
+		if err != nil {
+			return nil, err
+		}
+		return parseMatrix(b)
+	case c.ConfigMap != "":
+		namespace, name, err := splitKey(c.ConfigMap)
+		if err != nil {
+			return nil, err
+		}
+		cm, err := s.GetKube().GetClientset().CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		v, ok := cm.Data[c.ConfigMapKey]
+		if !ok {
+			return nil, fmt.Errorf("config map %s has no key %s", c.ConfigMap, c.ConfigMapKey)
+		}
+		return parseMatrix([]byte(v))
+	case c.Resource != "":
+		namespace, name, err := splitKey(c.Resource)
+		if err != nil {
+			return nil, err
+		}
+		b, err := s.GetKube().GetClientset().CoreV1().RESTClient().Get().
+			AbsPath("/apis", resourceGroup, resourceVersion, "namespaces", namespace, resourcePlural, name).
+			DoRaw()
+		if err != nil {
+			return nil, err
+		}
+		r := &latencyMatrix{}
+		if err := json.Unmarshal(b, r); err != nil {
+			return nil, err
+		}
+		return r.Spec.RTT, nil
+	case c.Probes:
+		return c.probeMatrix()
+	}
+	return c.Matrix, nil
+}
+
+// probeMatrix returns the mean round trip times measured by the probe agents within the probe window.
+func (c *Config) probeMatrix() (Matrix, error) {
+	config := c.Influx
+	config.DB = c.ProbeDB
+	i, err := influxclient.NewInfluxClient(config)
+	if err != nil {
+		return nil, err
+	}
+	defer i.Close()
+	r, err := i.QueryDB(fmt.Sprintf("SELECT mean(\"rtt\") FROM \"rtt\" WHERE time >= now() - %s GROUP BY \"src_location\", \"dst_location\"", c.ProbeWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	m := Matrix{}
+	if len(r) == 0 {
+		return m, nil
+	}
+	for _, s := range r[0].Series {
+		if len(s.Values) == 0 || len(s.Values[0]) < 2 || s.Values[0][1] == nil {
+			continue
+		}
+		v, err := s.Values[0][1].(json.Number).Float64()
+		if err != nil {
+			return nil, err
+		}
+		src, dst := s.Tags["src_location"], s.Tags["dst_location"]
+		if m[src] == nil {
+			m[src] = make(map[string]float64)
+		}
+		m[src][dst] = v
+	}
+	return m, nil
+}
+
+func parseMatrix(b []byte) (Matrix, error) {
+	m := Matrix{}
+	if err := yaml.UnmarshalStrict(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func splitKey(key string) (string, string, error) {
+	namespace, name, err := watch.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return namespace, name, nil
+}
@@ -0,0 +1,129 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package location
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CoordinatesAnnotation holds "latitude,longitude" of a node
+	CoordinatesAnnotation = "edge-scheduler/coordinates"
+	LatitudeLabel         = "edge-scheduler/latitude"
+	LongitudeLabel        = "edge-scheduler/longitude"
+
+	earthRadius = 6371.0
+)
+
+// Coordinates in degrees.
+type Coordinates struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+// distance returns the great-circle distance in km by the haversine formula.
+func distance(a Coordinates, b Coordinates) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func parseCoordinates(lat string, lon string) (Coordinates, error) {
+	c := Coordinates{}
+	var err error
+	if c.Latitude, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+		return c, err
+	}
+	if c.Longitude, err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil {
+		return c, err
+	}
+	if math.Abs(c.Latitude) > 90 || math.Abs(c.Longitude) > 180 {
+		return c, fmt.Errorf("coordinates %f,%f out of range", c.Latitude, c.Longitude)
+	}
+	return c, nil
+}
+
+// nodeCoordinates takes the coordinates from the node annotation, the node labels or the location of the node.
+func (c Config) nodeCoordinates(n *v1.Node, location string) (Coordinates, bool) {
+	if v, ok := n.Annotations[CoordinatesAnnotation]; ok {
+		if p := strings.Split(v, ","); len(p) == 2 {
+			if co, err := parseCoordinates(p[0], p[1]); err == nil {
+				return co, true
+			}
+		}
+		log.Warnf("invalid annotation %s=%s of node %s", CoordinatesAnnotation, v, n.Name)
+	}
+	lat, okLat := n.Labels[LatitudeLabel]
+	lon, okLon := n.Labels[LongitudeLabel]
+	if okLat && okLon {
+		if co, err := parseCoordinates(lat, lon); err == nil {
+			return co, true
+		}
+		log.Warnf("invalid coordinate labels of node %s", n.Name)
+	}
+	co, ok := c.Locations[location]
+	return co, ok
+}
+
+// scoreDistance rates nodes by their distance to the request sources, weighted by the share of requests.
+// The nearest node gets MaxScore, the farthest 0.
+func (c Config) scoreDistance(s middleware.Scheduler, w *kubeclient.Workload, nodes []*v1.Node) map[string]int {
+	scores := make(map[string]int)
+	shares, err := c.RequestShares(w)
+	if err != nil {
+		log.Warn(err.Error())
+		return scores
+	}
+	if len(shares) == 0 {
+		return scores
+	}
+
+	distances := make(map[string]float64)
+	min, max := math.Inf(1), 0.0
+	for _, n := range nodes {
+		l, _ := s.GetKube().GetLocationFromNode(n)
+		co, ok := c.nodeCoordinates(n, l)
+		if !ok {
+			log.Debugf("node %s has no coordinates", n.Name)
+			continue
+		}
+		d, known := 0.0, 0.0
+		for source, share := range shares {
+			if sc, ok := c.Locations[source]; ok {
+				d += share * distance(co, sc)
+				known += share
+			}
+		}
+		if known == 0 {
+			continue
+		}
+		// requests from sources without coordinates don't count
+		d /= known
+		distances[n.Name] = d
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+
+	for n, d := range distances {
+		if max > min {
+			scores[n] = int(math.Round(float64(middleware.MaxScore) * (max - d) / (max - min)))
+		} else {
+			scores[n] = middleware.MaxScore
+		}
+		log.Debugf("node %s is %.0f km away from the requests", n, d)
+	}
+	return scores
+}
@@ -11,12 +11,21 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location/influxclient"
-	appsv1 "k8s.io/api/apps/v1"
 )
 
-func getLocationRequestPercent(i *influxclient.InfluxClient, d *appsv1.Deployment, timeRange string, location string) (int, error) {
-	r, err := i.QueryDB(fmt.Sprintf("SELECT count(\"duration\") FROM \"request\" WHERE (\"app\" = '%s') AND (\"location\" = '%s') AND time >= now() - %s", d.Name, location, timeRange))
+// kindCondition matches the requests of the workload kind. Requests written before the agent
+// tagged the kind have none and count for deployments, which were the only workloads then.
+func kindCondition(w *kubeclient.Workload) string {
+	if w.Kind == kubeclient.KindDeployment {
+		return "(\"kind\" = 'Deployment' OR \"kind\" = '')"
+	}
+	return fmt.Sprintf("(\"kind\" = '%s')", w.Kind)
+}
+
+func getLocationRequestPercent(i *influxclient.InfluxClient, w *kubeclient.Workload, timeRange string, location string) (int, error) {
+	r, err := i.QueryDB(fmt.Sprintf("SELECT count(\"duration\") FROM \"request\" WHERE (\"app\" = '%s') AND %s AND (\"location\" = '%s') AND time >= now() - %s", w.Name, kindCondition(w), location, timeRange))
 	if err != nil {
 		return 0, err
 	}
@@ -28,9 +37,9 @@ func getLocationRequestPercent(i *influxclient.InfluxClient, d *appsv1.Deploymen
 	if err != nil {
 		return 0, err
 	}
-	// log.Debugf("deployment %s called %d times from location %s in timerange %s", d.Name, c, location, timeRange)
+	// log.Debugf("%s called %d times from location %s in timerange %s", w, c, location, timeRange)
 
-	r, err = i.QueryDB(fmt.Sprintf("SELECT count(\"duration\") FROM \"request\" WHERE (\"app\" = '%s') AND time >= now() - %s", d.Name, timeRange))
+	r, err = i.QueryDB(fmt.Sprintf("SELECT count(\"duration\") FROM \"request\" WHERE (\"app\" = '%s') AND %s AND time >= now() - %s", w.Name, kindCondition(w), timeRange))
 	if err != nil {
 		return 0, err
 	}
@@ -43,7 +52,31 @@ func getLocationRequestPercent(i *influxclient.InfluxClient, d *appsv1.Deploymen
 	if err != nil {
 		return 0, err
 	}
-	// log.Debugf("deployment %s called %d times from all locations in timerange %s", d.Name, a, timeRange)
+	// log.Debugf("%s called %d times from all locations in timerange %s", w, a, timeRange)
 
 	return int((c * 100) / a), nil
 }
+
+// getRequestCounts returns the number of requests by source location.
+func getRequestCounts(i *influxclient.InfluxClient, w *kubeclient.Workload, timeRange string) (map[string]int64, error) {
+	r, err := i.QueryDB(fmt.Sprintf("SELECT count(\"duration\") FROM \"request\" WHERE (\"app\" = '%s') AND %s AND time >= now() - %s GROUP BY \"location\"", w.Name, kindCondition(w), timeRange))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	if len(r) == 0 {
+		return counts, nil
+	}
+	for _, s := range r[0].Series {
+		if len(s.Values) == 0 || len(s.Values[0]) < 2 {
+			continue
+		}
+		c, err := s.Values[0][1].(json.Number).Int64()
+		if err != nil {
+			return nil, err
+		}
+		counts[s.Tags["location"]] += c
+	}
+	return counts, nil
+}
@@ -0,0 +1,97 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package location
+
+import (
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Hierarchy groups locations, the sites, into zones and zones into regions by node labels.
+// A site without requests gets a share of the points of the other sites in its zone, or else in its region.
+type Hierarchy struct {
+	ZoneLabel     string `yaml:"zoneLabel"`
+	RegionLabel   string `yaml:"regionLabel"`
+	ZonePercent   int    `yaml:"zonePercent"`
+	RegionPercent int    `yaml:"regionPercent"`
+}
+
+// levels is the site, zone and region of a node, zone and region may be empty.
+type levels struct {
+	site   string
+	zone   string
+	region string
+}
+
+func defaultHierarchy() Hierarchy {
+	return Hierarchy{
+		ZoneLabel:     "topology.kubernetes.io/zone",
+		RegionLabel:   "topology.kubernetes.io/region",
+		ZonePercent:   50,
+		RegionPercent: 25,
+	}
+}
+
+func (h Hierarchy) levels(s middleware.Scheduler, n *v1.Node) (levels, error) {
+	l, err := s.GetKube().GetLocationFromNode(n)
+	if err != nil {
+		return levels{}, err
+	}
+	return levels{
+		site:   l,
+		zone:   n.Labels[h.ZoneLabel],
+		region: n.Labels[h.RegionLabel],
+	}, nil
+}
+
+// names returns the site, zone and region, leaving out the unknown ones.
+func (l levels) names() []string {
+	var names []string
+	for _, n := range []string{l.site, l.zone, l.region} {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// sites returns the levels of every site in the cluster, including the sites of disabled nodes.
+func (h Hierarchy) sites(s middleware.Scheduler) map[string]levels {
+	sites := make(map[string]levels)
+	for _, n := range middleware.Nodes(s) {
+		l, err := h.levels(s, n)
+		if err != nil {
+			continue
+		}
+		sites[l.site] = l
+	}
+	return sites
+}
+
+// partialPoints returns the share of the points of the other sites in the zone of a site,
+// or if they have none, of the other sites in the region.
+func (h Hierarchy) partialPoints(l levels, sites map[string]levels, points func(site string) int) (int, string) {
+	if h.ZonePercent == 0 && h.RegionPercent == 0 {
+		return 0, ""
+	}
+	zone, region := 0, 0
+	for site, o := range sites {
+		if site == l.site {
+			continue
+		}
+		if l.zone != "" && h.ZonePercent != 0 && o.zone == l.zone && o.region == l.region {
+			zone += points(site)
+		} else if l.region != "" && h.RegionPercent != 0 && o.region == l.region {
+			region += points(site)
+		}
+	}
+	if zone > 0 {
+		return zone * h.ZonePercent / 100, "zone " + l.zone
+	}
+	return region * h.RegionPercent / 100, "region " + l.region
+}
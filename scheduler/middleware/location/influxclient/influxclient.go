@@ -8,33 +8,38 @@
 package influxclient
 
 import (
-	client "github.com/influxdata/influxdb/client/v2"
-	"github.com/namsral/flag"
-)
+	"time"
 
-var (
-	influxAddr     string
-	influxUser     string
-	influxPassword string
-	influxDB       string
+	client "github.com/influxdata/influxdb/client/v2"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
 )
 
-func init() {
-	flag.StringVar(&influxAddr, "influxAddr", "http://influxdb:8086", "influxdb address")
-	flag.StringVar(&influxUser, "influxUser", "influx", "influxdb user")
-	flag.StringVar(&influxPassword, "influxPassword", "influx", "influxdb user password")
-	flag.StringVar(&influxDB, "influxDB", "edgescheduler", "influxdb database")
+type Config struct {
+	Addr     string `yaml:"addr"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DB       string `yaml:"db"`
 }
 
 type InfluxClient struct {
 	client client.Client
+	db     string
 }
 
-func NewInfluxClient() (*InfluxClient, error) {
+func DefaultConfig() Config {
+	return Config{
+		Addr:     "http://influxdb:8086",
+		User:     "influx",
+		Password: "influx",
+		DB:       "edgescheduler",
+	}
+}
+
+func NewInfluxClient(config Config) (*InfluxClient, error) {
 	c, err := client.NewHTTPClient(client.HTTPConfig{
-		Addr:     influxAddr,
-		Username: influxUser,
-		Password: influxPassword,
+		Addr:     config.Addr,
+		Username: config.User,
+		Password: config.Password,
 	})
 	if err != nil {
 		return nil, err
@@ -42,14 +47,22 @@ func NewInfluxClient() (*InfluxClient, error) {
 
 	return &InfluxClient{
 		client: c,
+		db:     config.DB,
 	}, nil
 }
 
 func (i *InfluxClient) QueryDB(cmd string) (res []client.Result, err error) {
 	q := client.Query{
 		Command:  cmd,
-		Database: influxDB,
+		Database: i.db,
 	}
+	start := time.Now()
+	defer func() {
+		metrics.InfluxQueryDuration.Observe(metrics.Since(start))
+		if err != nil {
+			metrics.InfluxQueryErrors.Inc()
+		}
+	}()
 	if response, err := i.client.Query(q); err == nil {
 		if response.Error() != nil {
 			return res, response.Error()
@@ -11,113 +11,223 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/namsral/flag"
 	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location/influxclient"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 )
 
 const (
 	name = "location"
+
+	// ScoringMatch rewards nodes at the locations the requests come from
+	ScoringMatch = "match"
+	// ScoringDistance rewards nodes near the locations the requests come from
+	ScoringDistance = "distance"
 )
 
 var (
-	defaultLocation string
-	namespace       string
-	log             *logrus.Entry
-	timeRanges      = []struct {
-		time  string
-		multi int
-	}{
-		{time: "15m", multi: 3},
-		{time: "1h", multi: 2},
-		{time: "24h", multi: 1},
-	}
+	log *logrus.Entry
 )
 
+type Config struct {
+	DefaultLocation       string                 `yaml:"defaultLocation"`
+	DefaultLocationPoints int                    `yaml:"defaultLocationPoints"`
+	TimeRanges            []TimeRange            `yaml:"timeRanges"`
+	Influx                influxclient.Config    `yaml:"influx"`
+	Scoring               string                 `yaml:"scoring"`
+	Locations             map[string]Coordinates `yaml:"locations"`
+	Hierarchy             Hierarchy              `yaml:"hierarchy"`
+}
+
+// TimeRange weights the request share of a location within the time range by Multi relative to the highest Multi,
+// the first time range with requests counts.
+type TimeRange struct {
+	Time  string `yaml:"time"`
+	Multi int    `yaml:"multi"`
+}
+
 func init() {
-	flag.StringVar(&defaultLocation, "defaultLocation", "", "default location")
+	middleware.Register(name, New)
 }
 
-func Location(m middleware.Middleware) middleware.Middleware {
-	return func(s middleware.Scheduler, d *middleware.Data) {
-		log = s.Log(name)
-		defer m(s, d)
-
-		s.GetNodes().Mutex.Lock()
-		for _, k := range d.Prio.Keys() {
-			o, _ := s.GetNodes().Get(k)
-			n := o.(*v1.Node)
-			l, err := s.GetKube().GetLocationFromNode(n)
-			if err != nil {
-				log.Warn(err.Error())
-				continue
-			}
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	c := Config{
+		DefaultLocationPoints: 50,
+		TimeRanges: []TimeRange{
+			{Time: "15m", Multi: 3},
+			{Time: "1h", Multi: 2},
+			{Time: "24h", Multi: 1},
+		},
+		Influx:    influxclient.DefaultConfig(),
+		Scoring:   ScoringMatch,
+		Hierarchy: defaultHierarchy(),
+	}
+	if err := args.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid %s settings: %s", name, err.Error())
+	}
+	if c.Scoring != ScoringMatch && c.Scoring != ScoringDistance {
+		return nil, fmt.Errorf("invalid %s settings: scoring must be %s or %s", name, ScoringMatch, ScoringDistance)
+	}
+	return &middleware.Plugin{
+		Filter: c.Filter,
+		Score:  c.Score,
+	}, nil
+}
 
-			// best location
-			if p := getLocationPoints(d.Deployment, l); p != 0 {
-				log.Debugf("node %s gets %d points for placed at location %s", n.Name, p, l)
-				if err := d.Prio.Add(k, p); err != nil {
-					log.Warn(err.Error())
-				}
-			}
+// Filter disables nodes at locations the pod doesn't tolerate, a site is also
+// not tolerated if its zone or region is denied or is tolerated if they are allowed.
+func (c Config) Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	log = s.Log(name)
+	disabled := make(map[string]string)
+	for _, n := range nodes {
+		l, err := c.Hierarchy.levels(s, n)
+		if err != nil {
+			log.Warn(err.Error())
+			continue
+		}
+		if !isTolerated(d.Pod, l.names()...) {
+			disabled[n.Name] = fmt.Sprintf("location %s is not tolerated", strings.Join(l.names(), "/"))
+			log.Debugf("deny scheduling pod %s to node %s, because of location tolerances", d.Pod.Name, n.Name)
+		}
+	}
+	return disabled
+}
 
-			// default location
-			if defaultLocation != "" && l == defaultLocation {
-				log.Debugf("node %s gets 5 points for placed at default location %s", n.Name, l)
-				if err := d.Prio.Add(k, 5); err != nil {
-					log.Warn(err.Error())
-				}
+// Score rates nodes by the share of requests from their location, nodes at the default location get extra points.
+// A location without requests gets a part of the points of its zone or region, see Hierarchy.
+func (c Config) Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	log = s.Log(name)
+	if c.Scoring == ScoringDistance {
+		return c.scoreDistance(s, d.Workload, nodes)
+	}
+	scores := make(map[string]int)
+	locations := make(map[string]int)
+	points := func(l string) int {
+		p, ok := locations[l]
+		if !ok {
+			p = c.getLocationPoints(d.Workload, l)
+			locations[l] = p
+		}
+		return p
+	}
+	var sites map[string]levels
+	for _, n := range nodes {
+		lv, err := c.Hierarchy.levels(s, n)
+		if err != nil {
+			log.Warn(err.Error())
+			continue
+		}
+		l := lv.site
+
+		// best location
+		p := points(l)
+		if p != 0 {
+			log.Debugf("node %s gets %d points for placed at location %s", n.Name, p, l)
+		} else {
+			if sites == nil {
+				sites = c.Hierarchy.sites(s)
 			}
-
-			// location tolerances
-			if !isTolerated(d.Pod, l) {
-				d.Prio.Disable(k)
-				log.Debugf("deny scheduling pod %s to node %s, because of location tolerances", d.Pod.Name, k)
+			var level string
+			if p, level = c.Hierarchy.partialPoints(lv, sites, points); p != 0 {
+				log.Debugf("node %s gets %d points for placed in %s", n.Name, p, level)
 			}
 		}
-		s.GetNodes().Mutex.Unlock()
+
+		// default location
+		if c.DefaultLocation != "" && l == c.DefaultLocation {
+			log.Debugf("node %s gets %d points for placed at default location %s", n.Name, c.DefaultLocationPoints, l)
+			p += c.DefaultLocationPoints
+		}
+
+		if p > middleware.MaxScore {
+			p = middleware.MaxScore
+		}
+		scores[n.Name] = p
 	}
+	return scores
 }
 
-func getLocationPoints(d *appsv1.Deployment, location string) int {
-	i, err := influxclient.NewInfluxClient()
+// getLocationPoints returns the percentage of requests from the location in the first time range
+// with requests, scaled by its multiplier relative to the highest one, so it stays within MaxScore.
+func (c Config) getLocationPoints(w *kubeclient.Workload, location string) int {
+	maxMulti := 0
+	for _, r := range c.TimeRanges {
+		if r.Multi > maxMulti {
+			maxMulti = r.Multi
+		}
+	}
+	if maxMulti == 0 {
+		return 0
+	}
+
+	i, err := influxclient.NewInfluxClient(c.Influx)
 	if err != nil {
 		log.Warn(err.Error())
 		return 0
 	}
 	defer i.Close()
 
-	for _, r := range timeRanges {
-		p, err := getLocationRequestPercent(i, d, r.time, location)
+	for _, r := range c.TimeRanges {
+		p, err := getLocationRequestPercent(i, w, r.Time, location)
 		if err != nil {
 			log.Warn(err.Error())
 			break
 		} else if p != 0 {
-			return (p / 10) * r.multi
+			return p * r.Multi / maxMulti
 		}
 	}
 	return 0
 }
 
-func isTolerated(p *v1.Pod, location string) bool {
-	if d, err := isInLabel("deniedLocations", p, location); err == nil {
+// RequestShares returns the share of requests by source location in the first time range with requests.
+func (c Config) RequestShares(w *kubeclient.Workload) (map[string]float64, error) {
+	i, err := influxclient.NewInfluxClient(c.Influx)
+	if err != nil {
+		return nil, err
+	}
+	defer i.Close()
+
+	for _, r := range c.TimeRanges {
+		counts, err := getRequestCounts(i, w, r.Time)
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		for _, n := range counts {
+			total += n
+		}
+		if total == 0 {
+			continue
+		}
+		shares := make(map[string]float64)
+		for l, n := range counts {
+			shares[l] = float64(n) / float64(total)
+		}
+		return shares, nil
+	}
+	return nil, nil
+}
+
+func isTolerated(p *v1.Pod, locations ...string) bool {
+	if d, err := isInLabel("deniedLocations", p, locations...); err == nil {
 		return !d
-	} else if d, err := isInLabel("allowedLocations", p, location); err == nil {
+	} else if d, err := isInLabel("allowedLocations", p, locations...); err == nil {
 		return d
 	}
 	return true
 }
 
-func isInLabel(label string, p *v1.Pod, key string) (bool, error) {
+func isInLabel(label string, p *v1.Pod, keys ...string) (bool, error) {
 	if v, ok := p.Labels[label]; !ok {
 		return false, fmt.Errorf("label %s not set", label)
 	} else {
 		for _, k := range strings.Split(v, ",") {
-			if k == key {
-				return true, nil
+			for _, key := range keys {
+				if k == key {
+					return true, nil
+				}
 			}
 		}
 	}
@@ -8,13 +8,20 @@
 package middleware
 
 import (
+	"time"
+
 	"github.com/sirupsen/logrus"
 	"github.com/telekom/k8s-edge-scheduler/cache"
-	appsv1 "k8s.io/api/apps/v1"
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
+	yaml "gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+// LocationTopologyKey stands for the location of a node in topology keys, see GetLocationFromNode.
+const LocationTopologyKey = "location"
+
 type Middleware func(scheduler Scheduler, data *Data)
 
 type Adapter func(Middleware) Middleware
@@ -22,13 +29,14 @@ type Adapter func(Middleware) Middleware
 type Scheduler interface {
 	GetKube() KubernetesClient
 	GetNodes() *cache.Cache
-	GetNamespace() string
+	GetNodePods(node string) []*v1.Pod
+	GetNamespaces() []string
 	Log(component string) *logrus.Entry
 }
 
 type KubernetesClient interface {
 	GetClientset() *kubernetes.Clientset
-	GetPodsFromDeployment(d *appsv1.Deployment) ([]v1.Pod, error)
+	GetPodsFromWorkload(w *kubeclient.Workload) ([]v1.Pod, error)
 	GetLocationFromNode(n *v1.Node) (string, error)
 }
 
@@ -36,16 +44,37 @@ type PrioMap interface {
 	Get(k string) (int, error)
 	Add(k string, add int) error
 	Set(k string, v int) error
-	Disable(k string) error
+	Disable(k string, reason string) error
+	Scope(component string, weight float64) PrioMap
 	Keys() []string
 	ListInc() []PrioMapPair
 	ListDec() []PrioMapPair
 }
 
 type Data struct {
-	Pod        *v1.Pod
-	Deployment *appsv1.Deployment
-	Prio       PrioMap
+	Pod      *v1.Pod
+	Workload *kubeclient.Workload
+	Prio     PrioMap
+	// Err is set by the last handler of a chain if the pod could not be placed
+	Err error
+
+	// time spent in the rest of the chain, by middleware
+	downstream map[string]time.Duration
+	values     map[string]interface{}
+}
+
+// Store keeps a value for the rest of the run, e.g. to pass a result from the filter to the score phase.
+func (d *Data) Store(key string, v interface{}) {
+	if d.values == nil {
+		d.values = make(map[string]interface{})
+	}
+	d.values[key] = v
+}
+
+// Load returns a value stored earlier in the run.
+func (d *Data) Load(key string) (interface{}, bool) {
+	v, ok := d.values[key]
+	return v, ok
 }
 
 type PrioMapPair struct {
@@ -53,9 +82,85 @@ type PrioMapPair struct {
 	Value int
 }
 
+// Args holds the settings of a middleware from the scheduler configuration.
+type Args map[string]interface{}
+
+// Decode fills the settings into v, unknown settings are an error.
+func (a Args) Decode(v interface{}) error {
+	if len(a) == 0 {
+		return nil
+	}
+	b, err := yaml.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(b, v)
+}
+
+// Named attributes all changes an adapter makes to the priority map to the given name,
+// scales them by weight and measures the execution time of the adapter, without the rest of the chain.
+func Named(name string, weight float64, a Adapter) Adapter {
+	return func(m Middleware) Middleware {
+		next := a(func(s Scheduler, d *Data) {
+			start := time.Now()
+			scoped := d.Prio
+			d.Prio = scoped.Scope("", 1)
+			m(s, d)
+			d.Prio = scoped
+			if d.downstream == nil {
+				d.downstream = make(map[string]time.Duration)
+			}
+			d.downstream[name] += time.Since(start)
+		})
+		return func(s Scheduler, d *Data) {
+			start := time.Now()
+			prio := d.Prio
+			d.Prio = prio.Scope(name, weight)
+			next(s, d)
+			d.Prio = prio
+			metrics.MiddlewareDuration.WithLabelValues(name).Observe((time.Since(start) - d.downstream[name]).Seconds())
+		}
+	}
+}
+
 func Adapt(m Middleware, adapters ...Adapter) Middleware {
 	for i := range adapters {
 		m = adapters[len(adapters)-1-i](m)
 	}
 	return m
 }
+
+// TopologyValue returns the value of a topology key of a node.
+func TopologyValue(s Scheduler, n *v1.Node, key string) (string, bool) {
+	if key == LocationTopologyKey {
+		l, err := s.GetKube().GetLocationFromNode(n)
+		return l, err == nil
+	}
+	v, ok := n.Labels[key]
+	return v, ok
+}
+
+// Nodes returns all nodes of the cluster, including the ones disabled in this run.
+func Nodes(s Scheduler) []*v1.Node {
+	var nodes []*v1.Node
+	s.GetNodes().Mutex.Lock()
+	defer s.GetNodes().Mutex.Unlock()
+	for _, k := range s.GetNodes().Keys() {
+		if o, ok := s.GetNodes().Get(k); ok {
+			nodes = append(nodes, o.(*v1.Node))
+		}
+	}
+	return nodes
+}
+
+// OtherPods returns the pods on a node except the pod itself, which is already
+// on the node when the descheduler asks for the node the pod runs on.
+func OtherPods(s Scheduler, node string, pod *v1.Pod) []*v1.Pod {
+	var pods []*v1.Pod
+	for _, p := range s.GetNodePods(node) {
+		if p.Namespace != pod.Namespace || p.Name != pod.Name {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
@@ -0,0 +1,87 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package nodehealth
+
+import (
+	"fmt"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Name of the middleware, the descheduler moves pods off the nodes it disables regardless of the score.
+const Name = "nodehealth"
+
+// pressure conditions which make a node unusable if they are true
+var pressure = []v1.NodeConditionType{
+	v1.NodeMemoryPressure,
+	v1.NodeDiskPressure,
+	v1.NodePIDPressure,
+}
+
+type Config struct {
+	// IgnorePressure keeps nodes with memory, disk or pid pressure
+	IgnorePressure bool `yaml:"ignorePressure"`
+}
+
+func init() {
+	middleware.Register(Name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	c := Config{}
+	if err := args.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid %s settings: %s", Name, err.Error())
+	}
+	return &middleware.Plugin{
+		Filter: c.Filter,
+	}, nil
+}
+
+// Filter disables cordoned nodes, nodes which are not ready or unreachable, and nodes under pressure.
+func (c Config) Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	disabled := make(map[string]string)
+	for _, n := range nodes {
+		if reason := c.check(n); reason != "" {
+			disabled[n.Name] = reason
+		}
+	}
+	return disabled
+}
+
+func (c Config) check(n *v1.Node) string {
+	if n.Spec.Unschedulable {
+		return "node is cordoned"
+	}
+
+	conditions := make(map[v1.NodeConditionType]v1.ConditionStatus)
+	for _, cond := range n.Status.Conditions {
+		conditions[cond.Type] = cond.Status
+	}
+	switch conditions[v1.NodeReady] {
+	case v1.ConditionTrue:
+	case v1.ConditionUnknown:
+		return "node is unreachable"
+	default:
+		return "node is not ready"
+	}
+
+	if conditions[v1.NodeNetworkUnavailable] == v1.ConditionTrue {
+		return fmt.Sprintf("node has %s", v1.NodeNetworkUnavailable)
+	}
+
+	if c.IgnorePressure {
+		return ""
+	}
+	for _, t := range pressure {
+		if conditions[t] == v1.ConditionTrue {
+			return fmt.Sprintf("node has %s", t)
+		}
+	}
+	return ""
+}
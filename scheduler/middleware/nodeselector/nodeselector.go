@@ -8,36 +8,81 @@
 package nodeselector
 
 import (
+	"fmt"
+
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
 	v1 "k8s.io/api/core/v1"
 )
 
-func NodeSelector(m middleware.Middleware) middleware.Middleware {
-	return func(s middleware.Scheduler, d *middleware.Data) {
+const name = "nodeselector"
 
-		s.GetNodes().Mutex.Lock()
-		for _, k := range d.Prio.Keys() {
-			o, _ := s.GetNodes().Get(k)
-			n := o.(*v1.Node)
+func init() {
+	middleware.Register(name, New)
+}
 
-			// node selector
-			for l, v := range d.Pod.Spec.NodeSelector {
-				if a, ok := n.Labels[l]; !ok || a != v {
-					d.Prio.Disable(k)
-				}
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	return &middleware.Plugin{
+		Filter: Filter,
+		Score:  Score,
+	}, nil
+}
+
+// Filter disables nodes which don't match the node selector or have a taint the pod doesn't tolerate.
+func Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	disabled := make(map[string]string)
+nodes:
+	for _, n := range nodes {
+		// node selector
+		for l, v := range d.Pod.Spec.NodeSelector {
+			if a, ok := n.Labels[l]; !ok || a != v {
+				disabled[n.Name] = fmt.Sprintf("node selector %s=%s does not match", l, v)
+				continue nodes
 			}
+		}
+
+		// tolerations
+		if t := untolerated(n.Spec.Taints, d.Pod.Spec.Tolerations, v1.TaintEffectNoSchedule, v1.TaintEffectNoExecute); len(t) > 0 {
+			disabled[n.Name] = fmt.Sprintf("taint %s is not tolerated", t[0].ToString())
+		}
+	}
+	return disabled
+}
 
-			// tolerations
-			for _, t := range n.Spec.Taints {
-				for _, l := range d.Pod.Spec.Tolerations {
-					if t.Effect == "NoSchedule" &&
-						(t.Key != l.Key || t.Value != t.Value) {
-						d.Prio.Disable(k)
-					}
-				}
+// Score prefers nodes with less PreferNoSchedule taints the pod doesn't tolerate.
+func Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	scores := make(map[string]int)
+	for _, n := range nodes {
+		scores[n.Name] = middleware.MaxScore / (len(untolerated(n.Spec.Taints, d.Pod.Spec.Tolerations, v1.TaintEffectPreferNoSchedule)) + 1)
+	}
+	return scores
+}
+
+// untolerated returns the taints with one of the effects which none of the tolerations tolerates.
+func untolerated(taints []v1.Taint, tolerations []v1.Toleration, effects ...v1.TaintEffect) []v1.Taint {
+	var r []v1.Taint
+	for i := range taints {
+		if !hasEffect(taints[i], effects) {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
 			}
 		}
-		s.GetNodes().Mutex.Unlock()
-		m(s, d)
+		if !tolerated {
+			r = append(r, taints[i])
+		}
+	}
+	return r
+}
+
+func hasEffect(t v1.Taint, effects []v1.TaintEffect) bool {
+	for _, e := range effects {
+		if t.Effect == e {
+			return true
+		}
 	}
+	return false
 }
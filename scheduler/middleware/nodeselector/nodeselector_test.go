@@ -0,0 +1,247 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package nodeselector
+
+import (
+	"testing"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	gpuNoSchedule = v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}
+	gpuNoExecute  = v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoExecute}
+	edgePrefer    = v1.Taint{Key: "edge", Value: "far", Effect: v1.TaintEffectPreferNoSchedule}
+	diskPrefer    = v1.Taint{Key: "disk", Value: "slow", Effect: v1.TaintEffectPreferNoSchedule}
+)
+
+func TestUntolerated(t *testing.T) {
+	tests := []struct {
+		name        string
+		taints      []v1.Taint
+		tolerations []v1.Toleration
+		effects     []v1.TaintEffect
+		want        int
+	}{
+		{
+			name:    "no tolerations",
+			taints:  []v1.Taint{gpuNoSchedule},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+			want:    1,
+		},
+		{
+			name:   "equal matches key and value",
+			taints: []v1.Taint{gpuNoSchedule},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+		},
+		{
+			name:   "equal with another value",
+			taints: []v1.Taint{gpuNoSchedule},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "false", Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+			want:    1,
+		},
+		{
+			name:   "exists ignores the value",
+			taints: []v1.Taint{gpuNoSchedule},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+		},
+		{
+			name:   "exists with another key",
+			taints: []v1.Taint{gpuNoSchedule},
+			tolerations: []v1.Toleration{
+				{Key: "disk", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+			want:    1,
+		},
+		{
+			name:   "empty key with exists tolerates every key",
+			taints: []v1.Taint{gpuNoSchedule, edgePrefer},
+			tolerations: []v1.Toleration{
+				{Operator: v1.TolerationOpExists},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule},
+		},
+		{
+			name:   "empty effect tolerates every effect",
+			taints: []v1.Taint{gpuNoSchedule, gpuNoExecute},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true"},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule, v1.TaintEffectNoExecute},
+		},
+		{
+			name:   "other effect is not tolerated",
+			taints: []v1.Taint{gpuNoExecute},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoExecute},
+			want:    1,
+		},
+		{
+			name:    "taints with other effects are left out",
+			taints:  []v1.Taint{gpuNoExecute, edgePrefer},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule},
+		},
+		{
+			name:   "only one of several tolerations matches",
+			taints: []v1.Taint{gpuNoSchedule, edgePrefer},
+			tolerations: []v1.Toleration{
+				{Key: "disk", Operator: v1.TolerationOpExists},
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "false"},
+				{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule},
+			},
+			effects: []v1.TaintEffect{v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule},
+			want:    1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := untolerated(tt.taints, tt.tolerations, tt.effects...); len(got) != tt.want {
+				t.Errorf("got %d untolerated taints %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		taints       []v1.Taint
+		nodeSelector map[string]string
+		tolerations  []v1.Toleration
+		disabled     bool
+	}{
+		{
+			name: "no selector and no taints",
+		},
+		{
+			name:         "selector matches",
+			labels:       map[string]string{"disk": "ssd"},
+			nodeSelector: map[string]string{"disk": "ssd"},
+		},
+		{
+			name:         "selector with another value",
+			labels:       map[string]string{"disk": "hdd"},
+			nodeSelector: map[string]string{"disk": "ssd"},
+			disabled:     true,
+		},
+		{
+			name:         "selector label missing",
+			nodeSelector: map[string]string{"disk": "ssd"},
+			disabled:     true,
+		},
+		{
+			name:     "untolerated NoSchedule",
+			taints:   []v1.Taint{gpuNoSchedule},
+			disabled: true,
+		},
+		{
+			name:     "untolerated NoExecute",
+			taints:   []v1.Taint{gpuNoExecute},
+			disabled: true,
+		},
+		{
+			name:   "tolerated NoExecute",
+			taints: []v1.Taint{gpuNoExecute},
+			tolerations: []v1.Toleration{
+				{Key: "gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+			},
+		},
+		{
+			name:   "untolerated PreferNoSchedule only lowers the score",
+			taints: []v1.Taint{edgePrefer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node("node-1", tt.labels, tt.taints...)
+			d := &middleware.Data{Pod: pod(tt.nodeSelector, tt.tolerations...)}
+			disabled := Filter(nil, d, []*v1.Node{n})
+			if _, ok := disabled[n.Name]; ok != tt.disabled {
+				t.Errorf("got disabled %t (%v), want %t", ok, disabled, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		taints      []v1.Taint
+		tolerations []v1.Toleration
+		want        int
+	}{
+		{
+			name: "no taints",
+			want: middleware.MaxScore,
+		},
+		{
+			name:   "one untolerated PreferNoSchedule",
+			taints: []v1.Taint{edgePrefer},
+			want:   middleware.MaxScore / 2,
+		},
+		{
+			name:   "two untolerated PreferNoSchedule",
+			taints: []v1.Taint{edgePrefer, diskPrefer},
+			want:   middleware.MaxScore / 3,
+		},
+		{
+			name:   "tolerated PreferNoSchedule",
+			taints: []v1.Taint{edgePrefer, diskPrefer},
+			tolerations: []v1.Toleration{
+				{Key: "edge", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectPreferNoSchedule},
+			},
+			want: middleware.MaxScore / 2,
+		},
+		{
+			name:   "NoSchedule taints don't count",
+			taints: []v1.Taint{gpuNoSchedule},
+			want:   middleware.MaxScore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node("node-1", nil, tt.taints...)
+			d := &middleware.Data{Pod: pod(nil, tt.tolerations...)}
+			if got := Score(nil, d, []*v1.Node{n})[n.Name]; got != tt.want {
+				t.Errorf("got score %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func node(name string, labels map[string]string, taints ...v1.Taint) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       v1.NodeSpec{Taints: taints},
+	}
+}
+
+func pod(nodeSelector map[string]string, tolerations ...v1.Toleration) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec: v1.PodSpec{
+			NodeSelector: nodeSelector,
+			Tolerations:  tolerations,
+		},
+	}
+}
@@ -0,0 +1,135 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package middleware
+
+import (
+	"math"
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaxScore is the highest score a score plugin can give a node.
+	MaxScore = 100
+
+	baseComponent = "base"
+)
+
+// FilterFunc returns the reason for every node the pod must not be placed on.
+type FilterFunc func(s Scheduler, d *Data, nodes []*v1.Node) map[string]string
+
+// ScoreFunc rates every node from 0 to MaxScore. A plugin without opinion on a node leaves it out,
+// then its weight doesn't count for the node.
+type ScoreFunc func(s Scheduler, d *Data, nodes []*v1.Node) map[string]int
+
+// Plugin is created by a factory from the configuration. Filter and Score run in the filter
+// and score phase, which run before any Adapter. Adapters run in the configured order and
+// see the combined scores.
+type Plugin struct {
+	Filter  FilterFunc
+	Score   ScoreFunc
+	Adapter Adapter
+}
+
+// Entry is a configured plugin.
+type Entry struct {
+	Name   string
+	Weight float64
+	Plugin *Plugin
+}
+
+// Phases runs the filters of all entries, then the scores on the remaining nodes, and sets
+// the priority of every node to the weighted average of the scores it got. Unlike adapters,
+// the result does not depend on the order of the entries.
+func Phases(entries []Entry) Adapter {
+	return func(m Middleware) Middleware {
+		return func(s Scheduler, d *Data) {
+			durations := make(map[string]time.Duration)
+
+			nodes := enabledNodes(s, d)
+			for _, e := range entries {
+				if e.Plugin.Filter == nil || len(nodes) == 0 {
+					continue
+				}
+				start := time.Now()
+				disabled := e.Plugin.Filter(s, d, nodes)
+				durations[e.Name] += time.Since(start)
+
+				prio := d.Prio.Scope(e.Name, 1)
+				for k, reason := range disabled {
+					if err := prio.Disable(k, reason); err != nil {
+						s.Log(e.Name).Warn(err.Error())
+					}
+				}
+				if len(disabled) > 0 {
+					nodes = enabledNodes(s, d)
+				}
+			}
+
+			scored := false
+			scores := make(map[string]map[string]int)
+			for _, e := range entries {
+				if e.Plugin.Score == nil {
+					continue
+				}
+				scored = true
+				if len(nodes) == 0 {
+					continue
+				}
+				start := time.Now()
+				scores[e.Name] = e.Plugin.Score(s, d, nodes)
+				durations[e.Name] += time.Since(start)
+			}
+
+			if scored {
+				base := d.Prio.Scope(baseComponent, 1)
+				for _, n := range nodes {
+					base.Set(n.Name, 0)
+
+					var weights float64
+					for _, e := range entries {
+						if _, ok := scores[e.Name][n.Name]; ok && e.Plugin.Score != nil {
+							weights += e.Weight
+						}
+					}
+					for _, e := range entries {
+						v, ok := scores[e.Name][n.Name]
+						if !ok || e.Plugin.Score == nil {
+							continue
+						}
+						if v < 0 || v > MaxScore {
+							s.Log(e.Name).Warnf("score %d of node %s is out of range", v, n.Name)
+							v = int(math.Max(0, math.Min(MaxScore, float64(v))))
+						}
+						if share := int(math.Round(float64(v) * e.Weight / weights)); share != 0 {
+							d.Prio.Scope(e.Name, 1).Add(n.Name, share)
+						}
+					}
+				}
+			}
+
+			for name, duration := range durations {
+				metrics.MiddlewareDuration.WithLabelValues(name).Observe(duration.Seconds())
+			}
+			m(s, d)
+		}
+	}
+}
+
+// enabledNodes returns the nodes which are not disabled.
+func enabledNodes(s Scheduler, d *Data) []*v1.Node {
+	var nodes []*v1.Node
+	for _, n := range Nodes(s) {
+		if p, err := d.Prio.Get(n.Name); err == nil && p != -1 {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
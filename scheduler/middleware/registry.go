@@ -0,0 +1,56 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package middleware
+
+import (
+	"sort"
+	"sync"
+)
+
+// Factory creates a plugin from the args of its entry in the scheduler configuration.
+type Factory func(args Args) (*Plugin, error)
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[string]Factory)
+)
+
+// Register makes a middleware available under the given name in the scheduler configuration.
+// It is meant to be called from the init function of the middleware package and panics
+// if the name is empty or already registered.
+func Register(name string, factory Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if name == "" || factory == nil {
+		panic("middleware: Register needs a name and a factory")
+	}
+	if _, ok := registry[name]; ok {
+		panic("middleware: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Lookup returns the factory of a registered middleware.
+func Lookup(name string) (Factory, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Registered returns the sorted names of all registered middlewares.
+func Registered() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	var names []string
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
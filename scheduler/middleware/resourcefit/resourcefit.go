@@ -0,0 +1,134 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package resourcefit
+
+import (
+	"fmt"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const name = "resourcefit"
+
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	return &middleware.Plugin{
+		Filter: Filter,
+	}, nil
+}
+
+// Filter disables nodes without enough allocatable resources, pod capacity or free host ports for the pod.
+func Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	request := podRequests(d.Pod)
+	ports := hostPorts(d.Pod)
+
+	disabled := make(map[string]string)
+	for _, n := range nodes {
+		if reason := fits(n, middleware.OtherPods(s, n.Name, d.Pod), request, ports); reason != "" {
+			disabled[n.Name] = reason
+		}
+	}
+	return disabled
+}
+
+func fits(n *v1.Node, pods []*v1.Pod, request v1.ResourceList, ports []v1.ContainerPort) string {
+	allocatable := n.Status.Allocatable
+	if max, ok := allocatable[v1.ResourcePods]; ok && int64(len(pods)+1) > max.Value() {
+		return fmt.Sprintf("node runs already %d pods", len(pods))
+	}
+
+	used := v1.ResourceList{}
+	for _, p := range pods {
+		add(used, podRequests(p))
+	}
+	for r, q := range request {
+		if q.IsZero() {
+			continue
+		}
+		a := allocatable[r]
+		free := a.DeepCopy()
+		free.Sub(used[r])
+		if q.Cmp(free) > 0 {
+			return fmt.Sprintf("insufficient %s, requested %s of %s free", r, q.String(), free.String())
+		}
+	}
+
+	for _, p := range pods {
+		for _, used := range hostPorts(p) {
+			for _, port := range ports {
+				if conflicts(port, used) {
+					return fmt.Sprintf("host port %d/%s is in use", port.HostPort, port.Protocol)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// podRequests returns the resources a pod requests, which is the sum of its containers,
+// but at least the request of every init container since they run one after another.
+func podRequests(p *v1.Pod) v1.ResourceList {
+	r := v1.ResourceList{}
+	for _, c := range p.Spec.Containers {
+		add(r, c.Resources.Requests)
+	}
+	for _, c := range p.Spec.InitContainers {
+		for k, q := range c.Resources.Requests {
+			if v, ok := r[k]; !ok || q.Cmp(v) > 0 {
+				r[k] = q.DeepCopy()
+			}
+		}
+	}
+	return r
+}
+
+func add(r v1.ResourceList, l v1.ResourceList) {
+	for k, q := range l {
+		v, ok := r[k]
+		if !ok {
+			v = *resource.NewQuantity(0, q.Format)
+		}
+		v.Add(q)
+		r[k] = v
+	}
+}
+
+func hostPorts(p *v1.Pod) []v1.ContainerPort {
+	var ports []v1.ContainerPort
+	for _, c := range p.Spec.Containers {
+		for _, port := range c.Ports {
+			if port.HostPort > 0 {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+func conflicts(a v1.ContainerPort, b v1.ContainerPort) bool {
+	if a.HostPort != b.HostPort || protocol(a) != protocol(b) {
+		return false
+	}
+	return a.HostIP == b.HostIP || isAnyIP(a.HostIP) || isAnyIP(b.HostIP)
+}
+
+func protocol(p v1.ContainerPort) v1.Protocol {
+	if p.Protocol == "" {
+		return v1.ProtocolTCP
+	}
+	return p.Protocol
+}
+
+func isAnyIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0"
+}
@@ -0,0 +1,187 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package topologyspread
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	name = "topologyspread"
+
+	// Annotation holds the constraints as JSON list, since the pod spec of the
+	// Kubernetes api this scheduler is built with has no topologySpreadConstraints.
+	Annotation = "edge-scheduler/topology-spread-constraints"
+
+	DoNotSchedule  = "DoNotSchedule"
+	ScheduleAnyway = "ScheduleAnyway"
+)
+
+var (
+	log *logrus.Entry
+)
+
+// Constraint has the fields of a topologySpreadConstraint in the pod spec.
+type Constraint struct {
+	MaxSkew           int                   `json:"maxSkew"`
+	TopologyKey       string                `json:"topologyKey"`
+	WhenUnsatisfiable string                `json:"whenUnsatisfiable"`
+	LabelSelector     *metav1.LabelSelector `json:"labelSelector"`
+}
+
+func init() {
+	middleware.Register(name, New)
+}
+
+func New(args middleware.Args) (*middleware.Plugin, error) {
+	return &middleware.Plugin{
+		Filter: Filter,
+		Score:  Score,
+	}, nil
+}
+
+// Filter disables nodes in topology domains where the pod would exceed the max skew of a DoNotSchedule constraint.
+func Filter(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]string {
+	log = s.Log(name)
+	disabled := make(map[string]string)
+	for _, c := range constraints(d.Pod, DoNotSchedule) {
+		counts := countPods(s, d.Pod, c, nodes)
+		min := minCount(counts)
+		for _, n := range nodes {
+			v, ok := middleware.TopologyValue(s, n, c.TopologyKey)
+			if !ok {
+				disabled[n.Name] = fmt.Sprintf("node has no topology key %s", c.TopologyKey)
+			} else if skew := counts[v] + 1 - min; skew > c.MaxSkew {
+				disabled[n.Name] = fmt.Sprintf("skew %d in %s=%s exceeds max skew %d", skew, c.TopologyKey, v, c.MaxSkew)
+			}
+		}
+	}
+	return disabled
+}
+
+// Score prefers nodes in topology domains with less matching pods for ScheduleAnyway constraints.
+func Score(s middleware.Scheduler, d *middleware.Data, nodes []*v1.Node) map[string]int {
+	log = s.Log(name)
+	scores := make(map[string]int)
+	cs := constraints(d.Pod, ScheduleAnyway)
+	if len(cs) == 0 {
+		return scores
+	}
+
+	penalties := make(map[string]int)
+	for _, c := range cs {
+		counts := countPods(s, d.Pod, c, nodes)
+		max := 0
+		for _, v := range counts {
+			if v > max {
+				max = v
+			}
+		}
+		for _, n := range nodes {
+			if v, ok := middleware.TopologyValue(s, n, c.TopologyKey); ok {
+				penalties[n.Name] += counts[v]
+			} else {
+				// nodes without the key are the last choice
+				penalties[n.Name] += max + 1
+			}
+		}
+	}
+
+	min, max := -1, 0
+	for _, p := range penalties {
+		if min == -1 || p < min {
+			min = p
+		}
+		if p > max {
+			max = p
+		}
+	}
+	for n, p := range penalties {
+		if max > min {
+			scores[n] = (max - p) * middleware.MaxScore / (max - min)
+		} else {
+			scores[n] = middleware.MaxScore
+		}
+	}
+	return scores
+}
+
+func constraints(p *v1.Pod, when string) []Constraint {
+	v, ok := p.Annotations[Annotation]
+	if !ok {
+		return nil
+	}
+	var all []Constraint
+	if err := json.Unmarshal([]byte(v), &all); err != nil {
+		log.Warnf("invalid annotation %s of pod %s/%s: %s", Annotation, p.Namespace, p.Name, err.Error())
+		return nil
+	}
+	var r []Constraint
+	for _, c := range all {
+		if c.WhenUnsatisfiable == "" {
+			c.WhenUnsatisfiable = DoNotSchedule
+		}
+		if c.MaxSkew < 1 {
+			c.MaxSkew = 1
+		}
+		if c.WhenUnsatisfiable == when {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+// countPods returns the number of pods matching the constraint for every value of the topology key
+// the given nodes have, so domains the pod can't use, e.g. only with nodes filtered out before, don't count.
+// The pods on all nodes of such a domain count.
+func countPods(s middleware.Scheduler, pod *v1.Pod, c Constraint, nodes []*v1.Node) map[string]int {
+	selector, err := metav1.LabelSelectorAsSelector(c.LabelSelector)
+	if err != nil {
+		log.Warnf("invalid label selector of pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+		selector = labels.Nothing()
+	}
+
+	counts := make(map[string]int)
+	for _, n := range nodes {
+		if v, ok := middleware.TopologyValue(s, n, c.TopologyKey); ok {
+			counts[v] = 0
+		}
+	}
+	for _, n := range middleware.Nodes(s) {
+		v, ok := middleware.TopologyValue(s, n, c.TopologyKey)
+		if _, usable := counts[v]; !ok || !usable {
+			continue
+		}
+		for _, p := range middleware.OtherPods(s, n.Name, pod) {
+			if p.Namespace == pod.Namespace && selector.Matches(labels.Set(p.Labels)) {
+				counts[v]++
+			}
+		}
+	}
+	return counts
+}
+
+func minCount(counts map[string]int) int {
+	min := -1
+	for _, c := range counts {
+		if min == -1 || c < min {
+			min = c
+		}
+	}
+	if min == -1 {
+		return 0
+	}
+	return min
+}
@@ -0,0 +1,136 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	watch "k8s.io/client-go/tools/cache"
+)
+
+const allNamespaces = "*"
+
+// parseNamespaces splits a comma separated namespace list,
+// an empty result means all namespaces.
+func parseNamespaces(v string) []string {
+	var namespaces []string
+	for _, n := range strings.Split(v, ",") {
+		n = strings.TrimSpace(n)
+		if n == allNamespaces {
+			return nil
+		} else if n != "" {
+			namespaces = append(namespaces, n)
+		}
+	}
+	return namespaces
+}
+
+func (s *Scheduler) watchPods(stop <-chan struct{}) []watch.InformerSynced {
+	var controllers []watch.Controller
+
+	namespaces := s.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	s.pods = make(map[string]watch.Store)
+	for _, n := range namespaces {
+		podList := watch.NewListWatchFromClient(s.kube.GetClientset().CoreV1().RESTClient(), "pods", n, fields.Everything())
+		store, controller := watch.NewInformer(podList, &v1.Pod{}, time.Second*0, watch.ResourceEventHandlerFuncs{
+			AddFunc:    s.enqueuePod,
+			UpdateFunc: s.updatePod,
+		})
+		s.pods[n] = store
+		controllers = append(controllers, controller)
+	}
+
+	if s.namespaceSelector != nil {
+		namespaceList := watch.NewListWatchFromClient(s.kube.GetClientset().CoreV1().RESTClient(), "namespaces", "", fields.Everything())
+		var controller watch.Controller
+		s.namespaceStore, controller = watch.NewInformer(namespaceList, &v1.Namespace{}, time.Second*0, watch.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { s.requeuePendingPods() },
+			UpdateFunc: func(interface{}, interface{}) { s.requeuePendingPods() },
+		})
+		controllers = append(controllers, controller)
+	}
+
+	var synced []watch.InformerSynced
+	for _, c := range controllers {
+		go c.Run(stop)
+		synced = append(synced, c.HasSynced)
+	}
+	return synced
+}
+
+func (s *Scheduler) podStore(namespace string) watch.Store {
+	if store, ok := s.pods[namespace]; ok {
+		return store
+	}
+	return s.pods[metav1.NamespaceAll]
+}
+
+func (s *Scheduler) watchesNamespace(namespace string) bool {
+	if len(s.namespaces) > 0 {
+		found := false
+		for _, n := range s.namespaces {
+			if n == namespace {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if s.namespaceSelector == nil {
+		return true
+	}
+	o, exists, err := s.namespaceStore.GetByKey(namespace)
+	if err != nil || !exists {
+		return false
+	}
+	return s.namespaceSelector.Matches(labels.Set(o.(*v1.Namespace).Labels))
+}
+
+func (s *Scheduler) describeNamespaces() string {
+	d := "all namespaces"
+	if len(s.namespaces) > 0 {
+		d = "namespaces " + strings.Join(s.namespaces, ",")
+	}
+	if s.namespaceSelector != nil {
+		d += " matching " + s.namespaceSelector.String()
+	}
+	return d
+}
+
+// listPods returns the pods of all watched namespaces.
+func (s *Scheduler) listPods() ([]v1.Pod, error) {
+	namespaces := s.namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var pods []v1.Pod
+	for _, n := range namespaces {
+		l, err := s.kube.GetClientset().CoreV1().Pods(n).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range l.Items {
+			if s.watchesNamespace(p.Namespace) {
+				pods = append(pods, p)
+			}
+		}
+	}
+	return pods, nil
+}
@@ -0,0 +1,88 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/cache"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	watch "k8s.io/client-go/tools/cache"
+)
+
+const nodeIndex = "node"
+
+// time a bound pod counts for its node without being seen by the informer
+var assumeTimeout = 30 * time.Second
+
+// watchNodePods watches the running pods of all namespaces by node, since
+// pods of other schedulers and namespaces use the resources of a node as well.
+func (s *Scheduler) watchNodePods(stop <-chan struct{}) watch.InformerSynced {
+	podList := watch.NewListWatchFromClient(s.kube.GetClientset().CoreV1().RESTClient(), "pods", metav1.NamespaceAll,
+		fields.ParseSelectorOrDie("spec.nodeName!=,status.phase!="+string(v1.PodSucceeded)+",status.phase!="+string(v1.PodFailed)))
+	indexer, controller := watch.NewIndexerInformer(podList, &v1.Pod{}, time.Second*0, watch.ResourceEventHandlerFuncs{
+		AddFunc:    s.forgetAssumedPod,
+		UpdateFunc: func(_, o interface{}) { s.forgetAssumedPod(o) },
+	}, watch.Indexers{
+		nodeIndex: func(o interface{}) ([]string, error) {
+			return []string{o.(*v1.Pod).Spec.NodeName}, nil
+		},
+	})
+	s.nodePods = indexer
+	go controller.Run(stop)
+	return controller.HasSynced
+}
+
+func newAssumedPods() *cache.Cache {
+	c := cache.NewCache()
+	c.Timeout = assumeTimeout
+	return c
+}
+
+// assumePod counts a bound pod for its node until the informer sees it.
+func (s *Scheduler) assumePod(pod *v1.Pod, node string) {
+	p := pod.DeepCopy()
+	p.Spec.NodeName = node
+	s.assumed.Set(p.Namespace+"/"+p.Name, p)
+}
+
+func (s *Scheduler) forgetAssumedPod(o interface{}) {
+	if p, ok := o.(*v1.Pod); ok {
+		s.assumed.Delete(p.Namespace + "/" + p.Name)
+	}
+}
+
+// GetNodePods returns the pods bound to a node, including the ones this scheduler bound recently.
+func (s *Scheduler) GetNodePods(node string) []*v1.Pod {
+	var pods []*v1.Pod
+	seen := make(map[string]bool)
+	if s.nodePods != nil {
+		l, err := s.nodePods.ByIndex(nodeIndex, node)
+		if err != nil {
+			log.Warn(err.Error())
+		}
+		for _, o := range l {
+			p := o.(*v1.Pod)
+			seen[p.Namespace+"/"+p.Name] = true
+			pods = append(pods, p)
+		}
+	}
+	for _, k := range s.assumed.Keys() {
+		o, ok := s.assumed.Get(k)
+		if !ok {
+			s.assumed.Delete(k)
+			continue
+		}
+		if p := o.(*v1.Pod); p.Spec.NodeName == node && !seen[k] {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
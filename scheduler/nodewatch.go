@@ -11,23 +11,41 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/fields"
 	watch "k8s.io/client-go/tools/cache"
 )
 
-func (s *Scheduler) watchNodes() {
+func (s *Scheduler) watchNodes(stop <-chan struct{}) watch.InformerSynced {
 	nodeList := watch.NewListWatchFromClient(s.kube.GetClientset().CoreV1().RESTClient(), "nodes", "", fields.Everything())
 	_, controller := watch.NewInformer(nodeList, &v1.Node{}, time.Second*0, watch.ResourceEventHandlerFuncs{
 		AddFunc:    s.addNode,
+		UpdateFunc: s.updateNode,
 		DeleteFunc: s.deleteNode,
 	})
-	go controller.Run(make(chan struct{}))
+	go controller.Run(stop)
+	return controller.HasSynced
 }
 
 func (s *Scheduler) addNode(o interface{}) {
 	n := o.(*v1.Node)
 	log.Debugf("add node %s", n.Name)
 	s.nodes.Set(n.Name, n)
+	s.requeuePendingPods()
+}
+
+func (s *Scheduler) updateNode(old, new interface{}) {
+	o, n := old.(*v1.Node), new.(*v1.Node)
+	s.nodes.Set(n.Name, n)
+	// heartbeats update the node status all the time, only changes
+	// relevant for placement give pending pods another chance
+	if !equality.Semantic.DeepEqual(o.Labels, n.Labels) ||
+		!equality.Semantic.DeepEqual(o.Spec, n.Spec) ||
+		!equality.Semantic.DeepEqual(o.Status.Allocatable, n.Status.Allocatable) ||
+		!equality.Semantic.DeepEqual(conditions(o), conditions(n)) {
+		log.Debugf("update node %s", n.Name)
+		s.requeuePendingPods()
+	}
 }
 
 func (s *Scheduler) deleteNode(o interface{}) {
@@ -35,3 +53,12 @@ func (s *Scheduler) deleteNode(o interface{}) {
 	s.nodes.Delete(n.Name)
 	log.Debugf("delete node %s", n.Name)
 }
+
+// conditions returns the status of every node condition without the heartbeat times.
+func conditions(n *v1.Node) map[v1.NodeConditionType]v1.ConditionStatus {
+	c := make(map[v1.NodeConditionType]v1.ConditionStatus)
+	for _, cond := range n.Status.Conditions {
+		c[cond.Type] = cond.Status
+	}
+	return c
+}
@@ -8,50 +8,81 @@
 package scheduler
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (s *Scheduler) schedule(obj interface{}) {
-	pod := obj.(*v1.Pod)
-	if pod.Status.Phase == "Pending" && pod.Spec.SchedulerName == s.name {
-		d, err := s.kube.GetDeploymentFromPod(pod)
-		if err != nil {
-			log.Warnf("pod %s belongs to no deployment", pod.Name)
-			return
-		}
-
-		data := &middleware.Data{
-			Pod:        pod,
-			Deployment: d,
-		}
+// schedule places a pending pod. An error means that the pod should be tried again later.
+func (s *Scheduler) schedule(pod *v1.Pod) error {
+	w, err := s.kube.GetWorkloadFromPod(pod)
+	if err != nil {
+		log.Warnf("no workload for pod %s/%s: %s", pod.Namespace, pod.Name, err.Error())
+		metrics.SchedulingAttempts.WithLabelValues(metrics.ResultError).Inc()
+		return err
+	}
 
-		if m, ok := s.decisions.Get(d.Name); ok {
-			s.decisions.Delete(d.Name)
-			log.Debugf("found decision for deployment %s in cache", d.Name)
-			data.Prio = m.(*middleware.Data).Prio
-			s.bindPod(nil, data)
-			return
-		}
+	data := &middleware.Data{
+		Pod:      pod,
+		Workload: w,
+	}
 
-		data.Prio = priomap.NewNodePrioMap(s.nodes.Keys())
-		s.scheduleM(s, data)
+	if m, ok := s.decisions.Get(w.Key()); ok {
+		s.decisions.Delete(w.Key())
+		log.Debugf("found decision for %s in cache", w)
+		data.Prio = m.(*middleware.Data).Prio
+		s.bindPod(nil, data)
+		return data.Err
 	}
+
+	data.Prio = priomap.NewNodePrioMap(s.nodes.Keys())
+	s.getChains().scheduleM(s, data)
+	return data.Err
 }
 
 func (s *Scheduler) bindPod(_ middleware.Scheduler, d *middleware.Data) {
-	node := d.Prio.(*priomap.NodePrioMap).Max()
+	prio := d.Prio.(*priomap.NodePrioMap)
+	node := prio.Max()
 	if node == "" {
-		log.Warnf("no node found for pod %s", d.Pod.Name)
+		msg := unschedulableMessage(prio)
+		log.Warnf("no node found for pod %s/%s: %s", d.Pod.Namespace, d.Pod.Name, msg)
+		metrics.SchedulingAttempts.WithLabelValues(metrics.ResultUnschedulable).Inc()
+
+		decision := s.newDecision(actionUnschedulable, d, node)
+		decision.Error = msg
+		s.history.Add(decision)
+		if s.dryRun {
+			return
+		}
+		s.recorder.Event(d.Pod, v1.EventTypeWarning, reasonFailedScheduling, msg)
+		s.setUnschedulable(d.Pod, msg)
+		d.Err = fmt.Errorf("no node found for pod %s", d.Pod.Name)
+		return
+	}
+	decision := s.newDecision(actionBind, d, node)
+	log.Debugf("score breakdown for pod %s/%s: %+v", d.Pod.Namespace, d.Pod.Name, decision.Nodes)
+
+	if s.dryRun {
+		log.Infof("dry run: would bind pod %s/%s to node %s with score %d", d.Pod.Namespace, d.Pod.Name, node, decision.Score)
+		metrics.SchedulingAttempts.WithLabelValues(metrics.ResultDryRun).Inc()
+		s.history.Add(decision)
+		if s.shadow {
+			s.shadowed.Set(d.Pod.Namespace+"/"+d.Pod.Name, decision)
+		}
 		return
 	}
 
 	// bind pod
-	err := s.kube.GetClientset().CoreV1().Pods(s.namespace).Bind(&v1.Binding{
+	start := time.Now()
+	err := s.kube.GetClientset().CoreV1().Pods(d.Pod.Namespace).Bind(&v1.Binding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: d.Pod.Name,
+			Name:      d.Pod.Name,
+			Namespace: d.Pod.Namespace,
 		},
 		Target: v1.ObjectReference{
 			APIVersion: "v1",
@@ -59,9 +90,19 @@ func (s *Scheduler) bindPod(_ middleware.Scheduler, d *middleware.Data) {
 			Name:       node,
 		},
 	})
+	metrics.BindingDuration.Observe(metrics.Since(start))
 	if err != nil {
-		log.Warnf("could not bind pod %s to node %s: %s", d.Pod.Name, node, err.Error())
+		metrics.SchedulingAttempts.WithLabelValues(metrics.ResultError).Inc()
+		log.Warnf("could not bind pod %s/%s to node %s: %s", d.Pod.Namespace, d.Pod.Name, node, err.Error())
+		s.recorder.Eventf(d.Pod, v1.EventTypeWarning, reasonFailedScheduling, "Binding to node %s rejected: %s", node, err.Error())
+		d.Err = err
+		decision.Error = err.Error()
 	} else {
-		log.Infof("bind pod %s to node %s", d.Pod.Name, node)
+		metrics.SchedulingAttempts.WithLabelValues(metrics.ResultScheduled).Inc()
+		metrics.E2eSchedulingDuration.Observe(metrics.Since(d.Pod.CreationTimestamp.Time))
+		log.Infof("bind pod %s/%s to node %s", d.Pod.Namespace, d.Pod.Name, node)
+		s.assumePod(d.Pod, node)
+		s.recorder.Eventf(d.Pod, v1.EventTypeNormal, reasonScheduled, "Successfully assigned %s/%s to %s with score %d", d.Pod.Namespace, d.Pod.Name, node, decision.Score)
 	}
+	s.history.Add(decision)
 }
@@ -9,34 +9,90 @@ package priomap
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
 )
 
+const baseComponent = "base"
+
 var (
-	max = 100
-	min = 0
+	max  = 100
+	min  = 0
+	base = 20
 )
 
+// NodePrioMap holds the priority of every node. Copies returned by Scope
+// share the priorities, but attribute changes to another component and scale them by its weight.
 type NodePrioMap struct {
+	component string
+	weight    float64
+	state     *state
+}
+
+type state struct {
 	mutex sync.Mutex
 	data  map[string]int
+	steps map[string][]Step
+}
+
+// Reason explains why a component disabled a node.
+type Reason struct {
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// Step is a single change of a node priority.
+type Step struct {
+	Component string `json:"component"`
+	Change    int    `json:"change"`
+	Score     int    `json:"score"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Explanation breaks the priority of a node down to the contributing components.
+type Explanation struct {
+	Score      int            `json:"score"`
+	Components map[string]int `json:"components"`
+	Disabled   *Reason        `json:"disabled,omitempty"`
+	Steps      []Step         `json:"steps"`
 }
 
 func NewNodePrioMap(nodes []string) *NodePrioMap {
 	prio := make(map[string]int)
+	steps := make(map[string][]Step)
 	for _, n := range nodes {
-		prio[n] = 20
+		prio[n] = base
+		steps[n] = []Step{{
+			Component: baseComponent,
+			Change:    base,
+			Score:     base,
+		}}
 	}
 
 	return &NodePrioMap{
-		data: prio,
+		weight: 1,
+		state: &state{
+			data:  prio,
+			steps: steps,
+		},
 	}
 }
+
+func (n *NodePrioMap) Scope(component string, weight float64) middleware.PrioMap {
+	return &NodePrioMap{
+		component: component,
+		weight:    weight,
+		state:     n.state,
+	}
+}
+
 func (n *NodePrioMap) Get(node string) (int, error) {
-	if p, ok := n.data[node]; ok {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	if p, ok := n.state.data[node]; ok {
 		return p, nil
 	}
 	return 0, fmt.Errorf("node %s not in map", node)
@@ -46,9 +102,12 @@ func (n *NodePrioMap) Set(node string, v int) error {
 	if v > max || v < 0 {
 		return fmt.Errorf("can't set to more than %d or less than null points", max)
 	}
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	n.data[node] = v
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	if p, ok := n.state.data[node]; ok && p != -1 {
+		v = clamp(p + n.scale(v-p))
+	}
+	n.record(node, v, "")
 	return nil
 }
 
@@ -56,17 +115,11 @@ func (n *NodePrioMap) Add(node string, add int) error {
 	if add > max || add < -max {
 		return fmt.Errorf("can't add or reduce more than %d points", max)
 	}
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	if p, ok := n.data[node]; ok {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	if p, ok := n.state.data[node]; ok {
 		if p != -1 {
-			if np := p + add; np < min {
-				n.data[node] = min
-			} else if np > max {
-				n.data[node] = max
-			} else {
-				n.data[node] = np
-			}
+			n.record(node, clamp(p+n.scale(add)), "")
 			return nil
 		}
 		return fmt.Errorf("node %s is already disabled", node)
@@ -74,26 +127,100 @@ func (n *NodePrioMap) Add(node string, add int) error {
 	return fmt.Errorf("node %s not in map", node)
 }
 
-func (n *NodePrioMap) Disable(node string) error {
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	if _, ok := n.data[node]; ok {
-		n.data[node] = -1
+func (n *NodePrioMap) Disable(node string, reason string) error {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	if p, ok := n.state.data[node]; ok {
+		// keep the first reason, it's the one which made the node unusable
+		if p != -1 {
+			n.record(node, -1, reason)
+		}
 		return nil
 	}
 	return fmt.Errorf("node %s not in map", node)
 }
 
+func clamp(v int) int {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
+}
+
+// scale applies the weight of the component to a change.
+func (n *NodePrioMap) scale(change int) int {
+	if n.weight == 1 {
+		return change
+	}
+	return int(math.Round(float64(change) * n.weight))
+}
+
+// record sets the priority of a node and remembers the change, the caller must hold the lock.
+func (n *NodePrioMap) record(node string, v int, reason string) {
+	p := n.state.data[node]
+	n.state.data[node] = v
+	if p == v && reason == "" {
+		return
+	}
+	n.state.steps[node] = append(n.state.steps[node], Step{
+		Component: n.component,
+		Change:    v - p,
+		Score:     v,
+		Reason:    reason,
+	})
+}
+
+// Disabled returns the reason for every disabled node.
+func (n *NodePrioMap) Disabled() map[string]Reason {
+	r := make(map[string]Reason)
+	for k, e := range n.Explain() {
+		if e.Disabled != nil {
+			r[k] = *e.Disabled
+		}
+	}
+	return r
+}
+
+// Explain returns the score breakdown of every node.
+func (n *NodePrioMap) Explain() map[string]Explanation {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	r := make(map[string]Explanation)
+	for k, p := range n.state.data {
+		e := Explanation{
+			Score:      p,
+			Components: make(map[string]int),
+			Steps:      append([]Step(nil), n.state.steps[k]...),
+		}
+		for _, s := range e.Steps {
+			if s.Score == -1 {
+				if e.Disabled == nil {
+					e.Disabled = &Reason{
+						Component: s.Component,
+						Message:   s.Reason,
+					}
+				}
+				continue
+			}
+			e.Components[s.Component] += s.Change
+		}
+		r[k] = e
+	}
+	return r
+}
+
 func (n *NodePrioMap) Map() map[string]int {
-	return n.data
+	return n.state.data
 }
 
 func (n *NodePrioMap) Max() string {
 	var node string
 	max := -1
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	for n, p := range n.data {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	for n, p := range n.state.data {
 		if p > max {
 			node = n
 			max = p
@@ -120,9 +247,9 @@ func (n *NodePrioMap) ListInc() []middleware.PrioMapPair {
 
 func (n *NodePrioMap) List() []middleware.PrioMapPair {
 	var d []middleware.PrioMapPair
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	for k, v := range n.data {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	for k, v := range n.state.data {
 		d = append(d, middleware.PrioMapPair{
 			Key:   k,
 			Value: v,
@@ -133,9 +260,9 @@ func (n *NodePrioMap) List() []middleware.PrioMapPair {
 
 func (n *NodePrioMap) Keys() []string {
 	var keys []string
-	n.mutex.Lock()
-	defer n.mutex.Unlock()
-	for k := range n.data {
+	n.state.mutex.Lock()
+	defer n.state.mutex.Unlock()
+	for k := range n.state.data {
 		keys = append(keys, k)
 	}
 	return keys
@@ -0,0 +1,109 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/wait"
+	watch "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func (s *Scheduler) runQueue(stop <-chan struct{}) {
+	go func() {
+		<-stop
+		s.queue.ShutDown()
+	}()
+	go wait.Until(func() {
+		for s.processNextPod() {
+		}
+	}, time.Second, stop)
+}
+
+func newQueue(initial time.Duration, max time.Duration) workqueue.RateLimitingInterface {
+	return workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(initial, max), "pods")
+}
+
+func (s *Scheduler) processNextPod() bool {
+	key, quit := s.queue.Get()
+	if quit {
+		return false
+	}
+	defer s.queue.Done(key)
+
+	namespace, _, err := watch.SplitMetaNamespaceKey(key.(string))
+	if err != nil {
+		s.queue.Forget(key)
+		return true
+	}
+	o, exists, err := s.podStore(namespace).GetByKey(key.(string))
+	if err != nil || !exists {
+		s.queue.Forget(key)
+		return true
+	}
+	pod := o.(*v1.Pod)
+	if !s.isPending(pod) {
+		s.queue.Forget(key)
+		return true
+	}
+
+	if err := s.schedule(pod); err != nil {
+		log.Warnf("retry pod %s after %d attempts: %s", key, s.queue.NumRequeues(key)+1, err.Error())
+		s.queue.AddRateLimited(key)
+		return true
+	}
+	s.queue.Forget(key)
+	return true
+}
+
+func (s *Scheduler) isPending(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodPending && pod.Spec.NodeName == "" && (pod.Spec.SchedulerName == s.name || s.shadow)
+}
+
+func (s *Scheduler) enqueuePod(o interface{}) {
+	pod, ok := o.(*v1.Pod)
+	if !ok || s.queue == nil || !s.isPending(pod) || !s.watchesNamespace(pod.Namespace) {
+		return
+	}
+	key, err := watch.MetaNamespaceKeyFunc(pod)
+	if err != nil {
+		log.Warn(err.Error())
+		return
+	}
+	s.queue.Add(key)
+}
+
+func (s *Scheduler) updatePod(old, new interface{}) {
+	o, n := old.(*v1.Pod), new.(*v1.Pod)
+	if s.shadow && o.Spec.NodeName == "" && n.Spec.NodeName != "" {
+		s.compareShadowed(n)
+	}
+	// status updates, e.g. written by this scheduler itself, are no reason to try again
+	if equality.Semantic.DeepEqual(o.Spec, n.Spec) &&
+		equality.Semantic.DeepEqual(o.Labels, n.Labels) &&
+		equality.Semantic.DeepEqual(o.Annotations, n.Annotations) {
+		return
+	}
+	s.enqueuePod(n)
+}
+
+// requeuePendingPods moves all pending pods back to the active queue,
+// since a changed node could make them schedulable.
+func (s *Scheduler) requeuePendingPods() {
+	if s.queue == nil {
+		return
+	}
+	for _, store := range s.pods {
+		for _, o := range store.List() {
+			s.enqueuePod(o)
+		}
+	}
+}
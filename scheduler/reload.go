@@ -0,0 +1,109 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/config"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	watch "k8s.io/client-go/tools/cache"
+)
+
+type chains struct {
+	scheduleM   middleware.Middleware
+	descheduleM middleware.Middleware
+	scoreM      middleware.Middleware
+}
+
+// configSource returns the raw scheduler configuration.
+type configSource func() ([]byte, error)
+
+func (s *Scheduler) newConfigSource() (configSource, error) {
+	if configMap != "" {
+		namespace, name, err := watch.SplitMetaNamespaceKey(configMap)
+		if err != nil {
+			return nil, err
+		}
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		return func() ([]byte, error) {
+			cm, err := s.kube.GetClientset().CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			v, ok := cm.Data[configMapKey]
+			if !ok {
+				return nil, fmt.Errorf("config map %s/%s has no key %s", namespace, name, configMapKey)
+			}
+			return []byte(v), nil
+		}, nil
+	}
+	if configFile != "" {
+		return func() ([]byte, error) {
+			return ioutil.ReadFile(configFile)
+		}, nil
+	}
+	return func() ([]byte, error) {
+		return []byte(config.Default), nil
+	}, nil
+}
+
+func (s *Scheduler) getChains() *chains {
+	s.chainMutex.RLock()
+	defer s.chainMutex.RUnlock()
+	return s.chains
+}
+
+// loadConfig reads the configuration and swaps the middleware chains, if the configuration changed.
+func (s *Scheduler) loadConfig() error {
+	raw, err := s.configSource()
+	if err != nil {
+		return err
+	}
+	if s.chains != nil && bytes.Equal(raw, s.configRaw) {
+		return nil
+	}
+
+	c, err := config.Parse(raw)
+	if err != nil {
+		return err
+	}
+	ch, err := s.buildChains(c)
+	if err != nil {
+		return err
+	}
+
+	if s.config != nil {
+		if diff := config.Diff(s.config, c); len(diff) > 0 {
+			log.Infof("reload configuration:\n%s", strings.Join(diff, "\n"))
+		}
+	}
+
+	s.chainMutex.Lock()
+	s.chains = ch
+	s.chainMutex.Unlock()
+	s.config = c
+	s.configRaw = raw
+	return nil
+}
+
+func (s *Scheduler) watchConfig(interval time.Duration) {
+	for {
+		<-time.NewTimer(interval).C
+		if err := s.loadConfig(); err != nil {
+			log.Warnf("keep current configuration: %s", err.Error())
+		}
+	}
+}
@@ -8,49 +8,108 @@
 package scheduler
 
 import (
+	"os"
+	"sync"
 	"time"
 
 	"github.com/namsral/flag"
 	"github.com/sirupsen/logrus"
 	"github.com/telekom/k8s-edge-scheduler/cache"
+	"github.com/telekom/k8s-edge-scheduler/kubeclient"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/config"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
 	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
-	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/deploymentstatus"
-	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/location"
-	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware/nodeselector"
-	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	watch "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 )
 
 var (
 	log                *logrus.Entry
 	name               string
-	namespace          string
+	namespaces         string
+	namespaceSelector  string
 	descheduleInterval time.Duration
+	leaderElect        bool
+	leaseDuration      time.Duration
+	renewDeadline      time.Duration
+	retryPeriod        time.Duration
+	lockNamespace      string
+	identity           string
+	podBackoffInitial  time.Duration
+	podBackoffMax      time.Duration
+	decisionHistory    int
+	adminAddr          string
+	dryRun             bool
+	shadow             bool
+	configFile         string
+	configMap          string
+	configMapKey       string
+	configReload       time.Duration
+	extender           bool
 )
 
 func init() {
+	hostname, _ := os.Hostname()
+
 	flag.StringVar(&name, "name", "edge-scheduler", "scheduler name")
-	flag.StringVar(&namespace, "namespace", "default", "kubernetes namespace")
+	flag.StringVar(&namespaces, "namespaces", "default", "comma separated list of kubernetes namespaces to watch, * for all namespaces")
+	flag.StringVar(&namespaceSelector, "namespaceSelector", "", "only watch namespaces matching this label selector")
 	flag.DurationVar(&descheduleInterval, "descheduleInterval", time.Minute, "interval to check pods for descheduling")
+	flag.BoolVar(&leaderElect, "leaderElect", false, "enable leader election to run multiple scheduler replicas")
+	flag.DurationVar(&leaseDuration, "leaseDuration", 15*time.Second, "duration standby replicas wait before taking over leadership")
+	flag.DurationVar(&renewDeadline, "renewDeadline", 10*time.Second, "duration the leader retries to renew its lease before giving up")
+	flag.DurationVar(&retryPeriod, "retryPeriod", 2*time.Second, "duration between leader election attempts")
+	flag.StringVar(&lockNamespace, "lockNamespace", "default", "namespace of the leader election lock")
+	flag.StringVar(&identity, "identity", hostname, "identity of this replica in leader election")
+	flag.DurationVar(&podBackoffInitial, "podBackoffInitial", time.Second, "initial backoff for pods which could not be scheduled")
+	flag.DurationVar(&podBackoffMax, "podBackoffMax", time.Minute, "max backoff for pods which could not be scheduled")
+	flag.IntVar(&decisionHistory, "decisionHistory", 200, "number of scheduling decisions kept in memory")
+	flag.StringVar(&adminAddr, "adminAddr", ":8080", "address of the admin api, empty to disable")
+	flag.BoolVar(&dryRun, "dryRun", false, "only log and record binds and evictions instead of executing them")
+	flag.BoolVar(&shadow, "shadow", false, "dry run for pods of all schedulers to compare placements, implies dryRun")
+	flag.StringVar(&configFile, "config", "", "scheduler configuration file, the built-in default configuration is used if empty")
+	flag.StringVar(&configMap, "configMap", "", "read the scheduler configuration from this config map (namespace/name) instead of a file")
+	flag.StringVar(&configMapKey, "configMapKey", "config.yml", "key of the scheduler configuration in the config map")
+	flag.BoolVar(&extender, "extender", false, "only serve the schedule chain as kube-scheduler extender on the admin api instead of scheduling pods")
+	flag.DurationVar(&configReload, "configReload", 30*time.Second, "interval to check the configuration for changes, 0 to disable reloading")
 }
 
 type Scheduler struct {
 	name               string
-	namespace          string
+	namespaces         []string
+	namespaceSelector  labels.Selector
+	namespaceStore     watch.Store
 	kube               KubernetesClient
 	nodes              *cache.Cache
-	scheduleM          middleware.Middleware
-	descheduleM        middleware.Middleware
+	chains             *chains
+	chainMutex         sync.RWMutex
+	config             *config.Config
+	configRaw          []byte
+	configSource       configSource
 	descheduleInterval time.Duration
 	decisions          *cache.Cache
+	election           *electionConfig
+	queue              workqueue.RateLimitingInterface
+	pods               map[string]watch.Store
+	nodePods           watch.Indexer
+	assumed            *cache.Cache
+	recorder           record.EventRecorder
+	history            *history
+	adminAddr          string
+	state              int32
+	dryRun             bool
+	shadow             bool
+	shadowed           *cache.Cache
+	extender           bool
 }
 
 type KubernetesClient interface {
 	GetClientset() *kubernetes.Clientset
-	GetDeploymentFromPod(p *v1.Pod) (*appsv1.Deployment, error)
+	GetWorkloadFromPod(p *v1.Pod) (*kubeclient.Workload, error)
 }
 
 func NewScheduler(k KubernetesClient, l *logrus.Logger) *Scheduler {
@@ -60,54 +119,127 @@ func NewScheduler(k KubernetesClient, l *logrus.Logger) *Scheduler {
 
 	s := &Scheduler{
 		name:               name,
-		namespace:          namespace,
+		namespaces:         parseNamespaces(namespaces),
 		kube:               k,
 		nodes:              cache.NewCache(),
 		descheduleInterval: descheduleInterval,
 		decisions:          cache.NewCache(),
+		history:            newHistory(decisionHistory),
+		adminAddr:          adminAddr,
+		dryRun:             dryRun || shadow,
+		shadow:             shadow,
+		shadowed:           cache.NewCache(),
+		assumed:            newAssumedPods(),
+		extender:           extender,
+	}
+	if s.extender && s.adminAddr == "" {
+		log.Fatal("extender needs the admin api")
+	}
+	if namespaceSelector != "" {
+		selector, err := labels.Parse(namespaceSelector)
+		if err != nil {
+			log.Fatalf("invalid namespace selector %s: %s", namespaceSelector, err.Error())
+		}
+		s.namespaceSelector = selector
+	}
+	if leaderElect {
+		s.election = &electionConfig{
+			leaseDuration: leaseDuration,
+			renewDeadline: renewDeadline,
+			retryPeriod:   retryPeriod,
+			namespace:     lockNamespace,
+			identity:      identity,
+		}
 	}
 	s.nodes.Timeout = 0 * time.Second
+	s.shadowed.Timeout = 10 * time.Minute
 	s.decisions.Timeout = 0 * time.Second
+	metrics.RegisterDecisionCache(func() int {
+		return len(s.decisions.Keys())
+	})
+
+	source, err := s.newConfigSource()
+	if err != nil {
+		log.Fatalf("invalid configuration source: %s", err.Error())
+	}
+	s.configSource = source
+	if err := s.loadConfig(); err != nil {
+		log.Fatalf("could not load configuration: %s", err.Error())
+	}
 
 	return s
 }
 
 func (s *Scheduler) Start() {
-	s.scheduleM = middleware.Adapt(
-		s.bindPod,
-		nodeselector.NodeSelector,
-		location.Location,
-		deploymentstatus.DeploymentStatus,
-	)
-
-	s.descheduleM = middleware.Adapt(
-		s.evictPod,
-		nodeselector.NodeSelector,
-		location.Location,
-		deploymentstatus.DeploymentStatus,
-	)
-
-	s.watchNodes()
-
-	podList := watch.NewListWatchFromClient(s.kube.GetClientset().CoreV1().RESTClient(), "pods", s.namespace, fields.Everything())
-	_, controller := watch.NewInformer(podList, &v1.Pod{}, time.Second*0, watch.ResourceEventHandlerFuncs{
-		AddFunc: s.schedule,
-	})
-	log.Infof("watch as %s for new pods in namespace %s", s.name, s.namespace)
-	go controller.Run(make(chan struct{}))
+	s.recorder = s.newEventRecorder()
+
+	if s.adminAddr != "" {
+		go s.serve()
+	}
+	if configReload > 0 && (configFile != "" || configMap != "") {
+		go s.watchConfig(configReload)
+	}
+
+	// every replica answers extender calls, so every replica needs the caches
+	if s.extender && !s.syncCaches(make(chan struct{})) {
+		log.Fatal("could not sync caches")
+	}
+	if s.election != nil {
+		s.setState(stateStandby)
+		s.runLeaderElection(s.run)
+		return
+	}
+	s.run(make(chan struct{}))
+}
+
+func (s *Scheduler) run(stop <-chan struct{}) {
+	if !s.extender {
+		s.queue = newQueue(podBackoffInitial, podBackoffMax)
+		if !s.syncCaches(stop) {
+			return
+		}
+	}
+	s.setState(stateLeading)
+
+	if s.extender {
+		log.Infof("serve as kube-scheduler extender on %s", s.adminAddr)
+	} else if s.shadow {
+		log.Infof("shadow pods of all schedulers in %s", s.describeNamespaces())
+	} else {
+		log.Infof("watch as %s for new pods in %s", s.name, s.describeNamespaces())
+	}
+	if s.dryRun {
+		log.Info("dry run, pods are neither bound nor evicted")
+	}
+	// pods seen before all namespaces were known may have been skipped
+	s.requeuePendingPods()
+	if s.queue != nil {
+		s.runQueue(stop)
+	}
 
 	for {
 		s.deschedule()
-		<-time.NewTimer(s.descheduleInterval).C
+		select {
+		case <-stop:
+			return
+		case <-time.NewTimer(s.descheduleInterval).C:
+		}
 	}
 }
 
+// syncCaches starts the pod, node and node pod informers and waits until they are synced.
+func (s *Scheduler) syncCaches(stop <-chan struct{}) bool {
+	s.setState(stateSyncing)
+	synced := append(s.watchPods(stop), s.watchNodes(stop), s.watchNodePods(stop))
+	return watch.WaitForCacheSync(stop, synced...)
+}
+
 func (s *Scheduler) GetKube() middleware.KubernetesClient {
 	return s.kube.(middleware.KubernetesClient)
 }
 
-func (s *Scheduler) GetNamespace() string {
-	return s.namespace
+func (s *Scheduler) GetNamespaces() []string {
+	return s.namespaces
 }
 
 func (s *Scheduler) GetNodes() *cache.Cache {
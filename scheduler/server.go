@@ -0,0 +1,159 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/middleware"
+	"github.com/telekom/k8s-edge-scheduler/scheduler/priomap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	watch "k8s.io/client-go/tools/cache"
+)
+
+const (
+	stateStarting int32 = iota
+	stateStandby
+	stateSyncing
+	stateLeading
+)
+
+var stateNames = map[int32]string{
+	stateStarting: "starting",
+	stateStandby:  "standby",
+	stateSyncing:  "syncing",
+	stateLeading:  "leading",
+}
+
+// Score is the result of a dry run of the scheduling middlewares for a pod.
+type Score struct {
+	Pod      string                         `json:"pod"`
+	Workload string                         `json:"workload"`
+	Node     string                         `json:"node,omitempty"`
+	Score    int                            `json:"score"`
+	Nodes    map[string]priomap.Explanation `json:"nodes"`
+}
+
+func (s *Scheduler) setState(state int32) {
+	atomic.StoreInt32(&s.state, state)
+}
+
+func (s *Scheduler) getState() int32 {
+	return atomic.LoadInt32(&s.state)
+}
+
+func (s *Scheduler) serve() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/decisions", s.handleDecisions)
+	mux.HandleFunc("/score", s.handleScore)
+	mux.HandleFunc("/extender/filter", s.handleExtenderFilter)
+	mux.HandleFunc("/extender/prioritize", s.handleExtenderPrioritize)
+	mux.HandleFunc("/extender/preempt", s.handleExtenderPreempt)
+	mux.Handle("/metrics", metrics.Handler())
+
+	log.Infof("serve admin api on %s", s.adminAddr)
+	if err := http.ListenAndServe(s.adminAddr, mux); err != nil {
+		log.Fatalf("admin api failed: %s", err.Error())
+	}
+}
+
+func (s *Scheduler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz reports ready as soon as the replica either waits as standby
+// or has synced its caches as leader.
+func (s *Scheduler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	state := s.getState()
+	if state != stateStandby && state != stateLeading {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprintln(w, stateNames[state])
+}
+
+func (s *Scheduler) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	pod := r.URL.Query().Get("pod")
+	limit := -1
+	if v := r.URL.Query().Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid limit %s", v), http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	decisions := []*Decision{}
+	for _, d := range s.history.List() {
+		if limit >= 0 && len(decisions) >= limit {
+			break
+		}
+		if pod == "" || d.Pod == pod {
+			decisions = append(decisions, d)
+		}
+	}
+	writeJSON(w, decisions)
+}
+
+func (s *Scheduler) handleScore(w http.ResponseWriter, r *http.Request) {
+	if s.getState() != stateLeading {
+		http.Error(w, "scheduler is not leading", http.StatusServiceUnavailable)
+		return
+	}
+
+	namespace, name, err := watch.SplitMetaNamespaceKey(r.URL.Query().Get("pod"))
+	if err != nil || name == "" {
+		http.Error(w, "pod must be given as namespace/name", http.StatusBadRequest)
+		return
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	pod, err := s.kube.GetClientset().CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	workload, err := s.kube.GetWorkloadFromPod(pod)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("no workload for pod %s/%s: %s", namespace, name, err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	data := &middleware.Data{
+		Pod:      pod,
+		Workload: workload,
+		Prio:     priomap.NewNodePrioMap(s.nodes.Keys()),
+	}
+	s.getChains().scoreM(s, data)
+
+	prio := data.Prio.(*priomap.NodePrioMap)
+	score := &Score{
+		Pod:      namespace + "/" + name,
+		Workload: workload.Key(),
+		Node:     prio.Max(),
+		Nodes:    prio.Explain(),
+	}
+	score.Score, _ = prio.Get(score.Node)
+	writeJSON(w, score)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("could not write response: %s", err.Error())
+	}
+}
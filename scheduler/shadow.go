@@ -0,0 +1,48 @@
+// k8s-edge-scheduler : custom kubernetes scheduler for placing pods based on location data
+// Copyright (c) 2019, Lukas Steiner, Deutsche Telekom AG
+// contact: opensource@telekom.de
+
+// This file is licensed under the terms of the 3-Clause BSD License  [SPDX: BSD3-Clause].
+// For Details see the file LICENSE on the top level of the project repository.
+
+package scheduler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/telekom/k8s-edge-scheduler/scheduler/metrics"
+	v1 "k8s.io/api/core/v1"
+)
+
+// compareShadowed compares the placement of another scheduler with the node
+// this scheduler would have chosen in shadow mode.
+func (s *Scheduler) compareShadowed(pod *v1.Pod) {
+	key := pod.Namespace + "/" + pod.Name
+	o, ok := s.shadowed.Get(key)
+	if !ok {
+		return
+	}
+	s.shadowed.Delete(key)
+
+	would := o.(*Decision)
+	match := would.Node == pod.Spec.NodeName
+	metrics.ShadowPlacements.WithLabelValues(strconv.FormatBool(match)).Inc()
+	if match {
+		log.Infof("shadow: %s placed pod %s on node %s, same as %s", pod.Spec.SchedulerName, key, pod.Spec.NodeName, s.name)
+	} else {
+		log.Infof("shadow: %s placed pod %s on node %s, %s would choose node %s", pod.Spec.SchedulerName, key, pod.Spec.NodeName, s.name, would.Node)
+	}
+
+	s.history.Add(&Decision{
+		Time:     time.Now(),
+		Action:   actionCompare,
+		Pod:      key,
+		Workload: would.Workload,
+		Node:     would.Node,
+		Actual:   pod.Spec.NodeName,
+		Score:    would.Score,
+		DryRun:   true,
+		Nodes:    would.Nodes,
+	})
+}